@@ -7,7 +7,6 @@ import (
 	"syscall"
 
 	"plist/server"
-	"plist/utils"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/cobra"
@@ -28,8 +27,8 @@ func initCmd() *cobra.Command {
 
 			go func() {
 				server.Setup()
-				if err := server.Run(sanitizePort(os.Args)); utils.CheckErr(err) {
-					log.Fatal("error in server running")
+				if err := server.Run(sanitizePort(os.Args)); err != nil {
+					log.Fatalf("error in server running: %s", err)
 				}
 			}()
 
@@ -46,8 +45,8 @@ func initCmd() *cobra.Command {
 			}
 
 			log.Printf("interrupt received: %d shutting down...\n", intSig)
-			if err := server.Close(); utils.CheckErr(err) {
-				log.Fatal("failed to close server")
+			if err := server.Close(); err != nil {
+				log.Fatalf("failed to close server: %s", err)
 			}
 			log.Println("successfully closed server")
 		},
@@ -1,49 +1,325 @@
 package errors
 
+import (
+	stderrors "errors"
+	"fmt"
+	"net/http"
+)
+
 // Error codes.
 const (
-	UnsupportedPeriod    = 100
-	TimeRoundingError    = 101
-	TimezoneLoadingError = 102
-	TimeParsingError     = 103
-	AddingPeriodError    = 104
+	UnsupportedPeriod       = 100
+	TimeRoundingError       = 101
+	TimezoneLoadingError    = 102
+	TimeParsingError        = 103
+	AddingPeriodError       = 104
+	UnsupportedWeekStart    = 105
+	UnsupportedYearStart    = 106
+	RRuleParsingError       = 107
+	CronParsingError        = 108
+	UnsupportedDSTPolicy    = 109
+	UnsupportedInputFormat  = 110
+	UnsupportedOutputFormat = 111
+	UnsupportedMode         = 112
+	InvalidRange            = 113
+	RangeTooLarge           = 114
+	ValidationError         = 115
+	RequestCanceled         = 116
+	InvalidRequestBody      = 117
+	BatchTooLarge           = 118
+	InvalidLimit            = 119
+	InvalidCursor           = 120
+	ResultTooLarge          = 121
+	InvalidNumber           = 122
+	UnsortedTimestamps      = 123
+	UnsupportedFillPolicy   = 124
+	UnsupportedMethod       = 125
+	InvalidMaxGap           = 126
+	BodyTooLarge            = 127
 )
 
-// Error struct.
+// Error struct. It implements the error interface: errors.Is matches errors with the same code,
+// e.g. the sentinel errors below, and errors.Unwrap returns the underlying cause.
+//
+// Functions return *ErrResp rather than error, so that callers read the code, parameter and HTTP
+// status of a failure without a type assertion. As with any concrete error type, a nil *ErrResp
+// stored in an error variable is not a nil error: compare the result with nil before passing it
+// on as an error.
 type ErrResp struct {
 	Status string `json:"status"`
 	Desc   string `json:"desc"`
+
+	// Code is one of the error codes above.
+	Code int `json:"code"`
+
+	// Param names the request parameter that caused the error, if any.
+	Param string `json:"param,omitempty"`
+
+	// HTTPStatus is the HTTP status code of the error: 400 for malformed parameters, 422 for
+	// well-formed but unusable ones and 500 for internal failures.
+	HTTPStatus int `json:"-"`
+
+	// Errors lists every field error of a validation error.
+	Errors []*ErrResp `json:"errors,omitempty"`
+
+	// Cause is the underlying error, if any.
+	Cause error `json:"-"`
+}
+
+// Sentinel errors per error code, for use with errors.Is. They are constant codes, so callers
+// cannot change them, and match every error object with the same code.
+const (
+	ErrUnsupportedPeriod       = Code(UnsupportedPeriod)
+	ErrTimeRounding            = Code(TimeRoundingError)
+	ErrUnknownTimezone         = Code(TimezoneLoadingError)
+	ErrTimeParsing             = Code(TimeParsingError)
+	ErrAddingPeriod            = Code(AddingPeriodError)
+	ErrUnsupportedWeekStart    = Code(UnsupportedWeekStart)
+	ErrUnsupportedYearStart    = Code(UnsupportedYearStart)
+	ErrRRuleParsing            = Code(RRuleParsingError)
+	ErrCronParsing             = Code(CronParsingError)
+	ErrUnsupportedDSTPolicy    = Code(UnsupportedDSTPolicy)
+	ErrUnsupportedInputFormat  = Code(UnsupportedInputFormat)
+	ErrUnsupportedOutputFormat = Code(UnsupportedOutputFormat)
+	ErrUnsupportedMode         = Code(UnsupportedMode)
+	ErrInvalidRange            = Code(InvalidRange)
+	ErrRangeTooLarge           = Code(RangeTooLarge)
+	ErrValidation              = Code(ValidationError)
+	ErrRequestCanceled         = Code(RequestCanceled)
+	ErrInvalidRequestBody      = Code(InvalidRequestBody)
+	ErrBatchTooLarge           = Code(BatchTooLarge)
+	ErrInvalidLimit            = Code(InvalidLimit)
+	ErrInvalidCursor           = Code(InvalidCursor)
+	ErrResultTooLarge          = Code(ResultTooLarge)
+	ErrInvalidNumber           = Code(InvalidNumber)
+	ErrUnsortedTimestamps      = Code(UnsortedTimestamps)
+	ErrUnsupportedFillPolicy   = Code(UnsupportedFillPolicy)
+	ErrUnsupportedMethod       = Code(UnsupportedMethod)
+	ErrInvalidMaxGap           = Code(InvalidMaxGap)
+	ErrBodyTooLarge            = Code(BodyTooLarge)
+)
+
+// Code is an error code as an error, see the sentinel errors above.
+type Code int
+
+func (c Code) Error() string {
+	return errorMap[int(c)].Desc
+}
+
+func (e *ErrResp) Error() string {
+	return e.Desc
+}
+
+// Is reports whether the target is an error code or an error with the same code.
+func (e *ErrResp) Is(target error) bool {
+	switch t := target.(type) {
+	case Code:
+		return int(t) == e.Code
+	case *ErrResp:
+		return t != nil && t.Code == e.Code
+	}
+	return false
 }
 
-// Assign error codes to errResp struct.
-var ErrorMap = map[int]ErrResp{
+func (e *ErrResp) Unwrap() error {
+	return e.Cause
+}
+
+// Assign error codes to errResp struct. Error objects are copied from it by GetError, so it is
+// unexported to keep the descriptions of the codes fixed.
+var errorMap = map[int]ErrResp{
 	UnsupportedPeriod: {
-		Status: "error",
-		Desc:   "Failed to round time objects",
+		Status:     "error",
+		Desc:       "Unsupported period",
+		HTTPStatus: http.StatusBadRequest,
 	},
 	TimeRoundingError: {
-		Status: "error",
-		Desc:   "Failed to round time objects",
+		Status:     "error",
+		Desc:       "Failed to round time objects",
+		HTTPStatus: http.StatusInternalServerError,
 	},
 	TimezoneLoadingError: {
-		Status: "error",
-		Desc:   "Could not load given timezone location",
+		Status:     "error",
+		Desc:       "Could not load given timezone location",
+		HTTPStatus: http.StatusUnprocessableEntity,
 	},
 	TimeParsingError: {
-		Status: "error",
-		Desc:   "Could not parse time in go",
+		Status:     "error",
+		Desc:       "Could not parse time in go",
+		HTTPStatus: http.StatusBadRequest,
 	},
 	AddingPeriodError: {
-		Status: "error",
-		Desc:   "Could not add period to time object",
+		Status:     "error",
+		Desc:       "Could not add period to time object",
+		HTTPStatus: http.StatusInternalServerError,
+	},
+	UnsupportedWeekStart: {
+		Status:     "error",
+		Desc:       "Unsupported first day of the week",
+		HTTPStatus: http.StatusBadRequest,
+	},
+	UnsupportedYearStart: {
+		Status:     "error",
+		Desc:       "Unsupported first month of the year",
+		HTTPStatus: http.StatusBadRequest,
+	},
+	RRuleParsingError: {
+		Status:     "error",
+		Desc:       "Could not parse recurrence rule",
+		HTTPStatus: http.StatusBadRequest,
+	},
+	CronParsingError: {
+		Status:     "error",
+		Desc:       "Could not parse cron expression",
+		HTTPStatus: http.StatusBadRequest,
+	},
+	UnsupportedDSTPolicy: {
+		Status:     "error",
+		Desc:       "Unsupported daylight saving time policy",
+		HTTPStatus: http.StatusBadRequest,
+	},
+	UnsupportedInputFormat: {
+		Status:     "error",
+		Desc:       "Unsupported input time format",
+		HTTPStatus: http.StatusBadRequest,
+	},
+	UnsupportedOutputFormat: {
+		Status:     "error",
+		Desc:       "Unsupported output time format",
+		HTTPStatus: http.StatusBadRequest,
+	},
+	UnsupportedMode: {
+		Status:     "error",
+		Desc:       "Unsupported output mode",
+		HTTPStatus: http.StatusBadRequest,
+	},
+	InvalidRange: {
+		Status:     "error",
+		Desc:       "First time point is after the second one",
+		HTTPStatus: http.StatusUnprocessableEntity,
+	},
+	RangeTooLarge: {
+		Status:     "error",
+		Desc:       "Time range spans too many timestamps",
+		HTTPStatus: http.StatusUnprocessableEntity,
+	},
+	ValidationError: {
+		Status:     "error",
+		Desc:       "Invalid request parameters",
+		HTTPStatus: http.StatusBadRequest,
+	},
+	RequestCanceled: {
+		Status:     "error",
+		Desc:       "Request was canceled",
+		HTTPStatus: http.StatusServiceUnavailable,
+	},
+	InvalidRequestBody: {
+		Status:     "error",
+		Desc:       "Could not decode request body",
+		HTTPStatus: http.StatusBadRequest,
+	},
+	BatchTooLarge: {
+		Status:     "error",
+		Desc:       "Batch has too many queries",
+		HTTPStatus: http.StatusRequestEntityTooLarge,
+	},
+	InvalidLimit: {
+		Status:     "error",
+		Desc:       "Invalid limit",
+		HTTPStatus: http.StatusBadRequest,
+	},
+	InvalidCursor: {
+		Status:     "error",
+		Desc:       "Invalid cursor",
+		HTTPStatus: http.StatusBadRequest,
+	},
+	ResultTooLarge: {
+		Status:     "error",
+		Desc:       "Result exceeds the maximum number of timestamps, page through it with limit and cursor",
+		HTTPStatus: http.StatusUnprocessableEntity,
+	},
+	InvalidNumber: {
+		Status:     "error",
+		Desc:       "Invalid number of occurrences",
+		HTTPStatus: http.StatusBadRequest,
+	},
+	UnsortedTimestamps: {
+		Status:     "error",
+		Desc:       "Timestamps are not in ascending order",
+		HTTPStatus: http.StatusUnprocessableEntity,
+	},
+	UnsupportedFillPolicy: {
+		Status:     "error",
+		Desc:       "Unsupported fill policy",
+		HTTPStatus: http.StatusBadRequest,
+	},
+	UnsupportedMethod: {
+		Status:     "error",
+		Desc:       "Unsupported resampling method",
+		HTTPStatus: http.StatusBadRequest,
+	},
+	InvalidMaxGap: {
+		Status:     "error",
+		Desc:       "Invalid maximum gap",
+		HTTPStatus: http.StatusBadRequest,
+	},
+	BodyTooLarge: {
+		Status:     "error",
+		Desc:       "Request body is too large",
+		HTTPStatus: http.StatusRequestEntityTooLarge,
 	},
 }
 
 // Retrieve a new error object.
 func GetError(errorCode int) *ErrResp {
 	resp := &ErrResp{
-		Status: ErrorMap[errorCode].Status,
-		Desc:   ErrorMap[errorCode].Desc,
+		Status:     errorMap[errorCode].Status,
+		Desc:       errorMap[errorCode].Desc,
+		Code:       errorCode,
+		HTTPStatus: errorMap[errorCode].HTTPStatus,
+	}
+	return resp
+}
+
+// Wrap retrieves a new error object caused by the given error, its message is appended to the description.
+func Wrap(errorCode int, cause error) *ErrResp {
+	resp := GetError(errorCode)
+	resp.Desc = fmt.Sprintf("%s: %s", resp.Desc, cause)
+	resp.Cause = cause
+	return resp
+}
+
+// Is reports whether any error in err's chain matches target, see the standard errors package.
+func Is(err, target error) bool {
+	return stderrors.Is(err, target)
+}
+
+// As finds the first error in err's chain that matches target, see the standard errors package.
+func As(err error, target interface{}) bool {
+	return stderrors.As(err, target)
+}
+
+// Join combines field errors into one error object. It returns nil without errors, the error
+// itself for a single error and a validation error listing all of them otherwise. Errors.Is
+// matches the validation error as well as each of its field errors.
+func Join(errResps []*ErrResp) *ErrResp {
+	switch len(errResps) {
+	case 0:
+		return nil
+	case 1:
+		return errResps[0]
+	}
+
+	resp := GetError(ValidationError)
+	resp.Errors = errResps
+	resp.HTTPStatus = http.StatusUnprocessableEntity
+	causes := make([]error, len(errResps))
+	for i, errResp := range errResps {
+		causes[i] = errResp
+		if errResp.HTTPStatus != http.StatusUnprocessableEntity {
+			resp.HTTPStatus = http.StatusBadRequest
+		}
 	}
+	resp.Cause = stderrors.Join(causes...)
 	return resp
 }
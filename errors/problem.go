@@ -0,0 +1,38 @@
+package errors
+
+import "fmt"
+
+// ProblemContentType is the media type of RFC 7807 problem documents.
+const ProblemContentType = "application/problem+json"
+
+// ProblemTypeBase prefixes the error code in the type URI of problem documents.
+const ProblemTypeBase = "urn:plist:error:"
+
+// Problem is an RFC 7807 problem document, with the error code and parameter as extension members.
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Code     int    `json:"code"`
+	Param    string `json:"param,omitempty"`
+
+	// Errors lists every field error of a validation error.
+	Errors []*ErrResp `json:"errors,omitempty"`
+}
+
+// Problem renders the error as a problem document about the given instance, e.g. the request URI.
+// The title is the generic description of the error code and the detail the actual description.
+func (e *ErrResp) Problem(instance string) *Problem {
+	return &Problem{
+		Type:     fmt.Sprintf("%s%d", ProblemTypeBase, e.Code),
+		Title:    errorMap[e.Code].Desc,
+		Status:   e.HTTPStatus,
+		Detail:   e.Desc,
+		Instance: instance,
+		Code:     e.Code,
+		Param:    e.Param,
+		Errors:   e.Errors,
+	}
+}
@@ -0,0 +1,195 @@
+package ptlist
+
+import (
+	"context"
+	"fmt"
+	"plist/errors"
+	"plist/utils"
+	"sort"
+	"time"
+)
+
+// Fill policies for empty periods.
+const (
+	FillNull         = "null"
+	FillZero         = "zero"
+	FillCarryForward = "carry-forward"
+)
+
+// parseFill parses a fill policy, null when empty.
+func parseFill(fill string) (string, *errors.ErrResp) {
+	switch fill {
+	case "":
+		return FillNull, nil
+	case FillNull, FillZero, FillCarryForward:
+		return fill, nil
+	}
+	return "", errors.GetError(errors.UnsupportedFillPolicy)
+}
+
+// Aggregate validates a request and rolls the values of a time series up into the periods
+// GetPtList lists in the intervals mode between 2 time points. Points outside these periods are
+// left out and points of the same time keep their order. Empty periods follow the fill policy
+// option: null values, zero values or the values of the last non-empty period.
+func (s *Service) Aggregate(ctx context.Context, period, tz, t1, t2 string, points []Point, opts ...Option) (*AggregateResponse, *errors.ErrResp) {
+	if errResp := s.Validate(ctx, PtListRequest{Period: period, TZ: tz, T1: t1, T2: t2}, opts...); errResp != nil {
+		return nil, errResp
+	}
+
+	o := newOptions(opts)
+	fill, errResp := parseFill(o.fill)
+	if errResp != nil {
+		errResp.Param = "fill"
+		return nil, errResp
+	}
+	format, errResp := utils.ParseOutputFormat(o.output)
+	if errResp != nil {
+		errResp.Param = "output_format"
+		return nil, errResp
+	}
+	calendar, errResp := o.calendar()
+	if errResp != nil {
+		return nil, errResp
+	}
+	loc, inputFormat, errResp := parseLocation(tz, o.input)
+	if errResp != nil {
+		return nil, errResp
+	}
+
+	series, errResp := s.parseSeries(points, loc, calendar, inputFormat)
+	if errResp != nil {
+		return nil, errResp
+	}
+
+	resp := &AggregateResponse{
+		Periods: []Aggregate{},
+	}
+	var last *Aggregate
+	errResp = s.intervals(ctx, period, tz, t1, t2, opts, func(start, end time.Time, label string) {
+		aggregate := Aggregate{
+			Interval: Interval{
+				Start: utils.FormatTime(start, format, loc),
+				End:   utils.FormatTime(end, format, loc),
+				Label: label,
+			},
+		}
+
+		for len(series) > 0 && series[0].timeObj.Before(end) {
+			if !series[0].timeObj.Before(start) {
+				aggregate.add(series[0].value)
+			}
+			series = series[1:]
+		}
+
+		switch {
+		case aggregate.Count > 0:
+			last = &aggregate
+		case fill == FillZero:
+			aggregate.fill(0)
+		case fill == FillCarryForward && last != nil:
+			aggregate.carry(last)
+		}
+		resp.Periods = append(resp.Periods, aggregate)
+	})
+	if errResp != nil {
+		return nil, errResp
+	}
+	return resp, nil
+}
+
+// add adds a value to the statistics of a period.
+func (a *Aggregate) add(value float64) {
+	if a.Count == 0 {
+		a.fill(value)
+		a.Count = 1
+		return
+	}
+
+	a.Count++
+	*a.Sum += value
+	*a.Avg = *a.Sum / float64(a.Count)
+	if value < *a.Min {
+		*a.Min = value
+	}
+	if value > *a.Max {
+		*a.Max = value
+	}
+	*a.Last = value
+}
+
+// fill sets every statistic but the count to a value.
+func (a *Aggregate) fill(value float64) {
+	values := make([]float64, 6)
+	for i := range values {
+		values[i] = value
+	}
+	a.Sum, a.Avg, a.Min, a.Max, a.First, a.Last = &values[0], &values[1], &values[2], &values[3], &values[4], &values[5]
+}
+
+// carry sets every statistic but the count to those of another period.
+func (a *Aggregate) carry(from *Aggregate) {
+	a.Sum, a.Avg, a.Min, a.Max, a.First, a.Last = from.Sum, from.Avg, from.Min, from.Max, from.First, from.Last
+}
+
+// seriesPoint is a single parsed point of a time series.
+type seriesPoint struct {
+	timeObj time.Time
+	value   float64
+}
+
+// parseSeries parses the points of a request body in UTC and sorts them in time order,
+// reporting every invalid timestamp.
+func (s *Service) parseSeries(points []Point, loc *time.Location, calendar utils.Calendar, inputFormat string) ([]seriesPoint, *errors.ErrResp) {
+	if errResp := s.checkPoints(len(points), "points"); errResp != nil {
+		return nil, errResp
+	}
+
+	parsed := make([]seriesPoint, len(points))
+	var errResps []*errors.ErrResp
+	for i, point := range points {
+		timeObj, errResp := s.parseTime(point.Timestamp, loc, calendar, inputFormat)
+		if errResp != nil {
+			errResp.Param = fmt.Sprintf("points[%d].timestamp", i)
+			errResps = append(errResps, errResp)
+			continue
+		}
+		parsed[i] = seriesPoint{timeObj: timeObj, value: point.Value}
+	}
+	if errResp := errors.Join(errResps); errResp != nil {
+		return nil, errResp
+	}
+
+	sort.SliceStable(parsed, func(i, j int) bool {
+		return parsed[i].timeObj.Before(parsed[j].timeObj)
+	})
+	return parsed, nil
+}
+
+// intervals calls fn with the start, end and label of each period GetPtList lists in the
+// intervals mode between 2 time points, in order. Like lists without a limit, ranges with more than
+// the maximum limit of periods fail.
+func (s *Service) intervals(ctx context.Context, period, tz, t1, t2 string, opts []Option, fn func(start, end time.Time, label string)) *errors.ErrResp {
+	var count int
+	var tooLarge bool
+	instants := func(start, end time.Time, label string) bool {
+		if count == s.maxLimit {
+			tooLarge = true
+			return false
+		}
+		count++
+		fn(start, end, label)
+		return true
+	}
+
+	opts = append(opts, withInstants(instants), WithLocal(false), WithMode(ModeIntervals), WithLimit(""), WithCursor(""))
+	if _, errResp := s.ptList(ctx, period, tz, t1, t2, nil, opts); errResp != nil {
+		return errResp
+	}
+	if tooLarge {
+		errResp := errors.GetError(errors.ResultTooLarge)
+		errResp.Desc = fmt.Sprintf("Result exceeds the maximum number of periods: at most %d", s.maxLimit)
+		errResp.Param = "t2"
+		return errResp
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package ptlist
+
+import (
+	"context"
+	"fmt"
+	"plist/errors"
+	"sync"
+)
+
+// Batch defaults.
+const (
+	DefaultBatchWorkers = 8
+	DefaultBatchLimit   = 1000
+	DefaultBatchEntries = 100000
+)
+
+// WithBatchWorkers sets the maximum number of batch queries listed concurrently.
+func WithBatchWorkers(workers int) ServiceOption {
+	return func(s *Service) {
+		s.batchWorkers = workers
+	}
+}
+
+// WithBatchLimit sets the maximum number of queries in a batch.
+func WithBatchLimit(limit int) ServiceOption {
+	return func(s *Service) {
+		s.batchLimit = limit
+	}
+}
+
+// WithBatchEntries sets the maximum number of timestamps or intervals in all the results of a batch.
+func WithBatchEntries(entries int) ServiceOption {
+	return func(s *Service) {
+		s.batchEntries = entries
+	}
+}
+
+// GetBatch lists many queries concurrently, on at most as many workers as the batch worker limit
+// of the service, and returns their results in order. Queries fail with a request canceled error
+// once the context is done and with a result too large error once the results of the queries up to
+// them hold more entries than the batch entry limit of the service, whatever order they finish in.
+func (s *Service) GetBatch(ctx context.Context, queries []BatchQuery) ([]BatchResult, *errors.ErrResp) {
+	if len(queries) > s.batchLimit {
+		return nil, errors.GetError(errors.BatchTooLarge)
+	}
+
+	results := make([]BatchResult, len(queries))
+	indexes := make(chan int)
+
+	// entries holds the number of timestamps or intervals each finished query listed.
+	entries := make([]int64, len(queries))
+	var mu sync.Mutex
+	exceeded := func(i int) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		var total int64
+		for _, n := range entries[:i] {
+			total += n
+		}
+		return total > int64(s.batchEntries)
+	}
+
+	workers := s.batchWorkers
+	if workers > len(queries) {
+		workers = len(queries)
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := ctx.Err(); err != nil {
+					results[i].Error = errors.Wrap(errors.RequestCanceled, err)
+					continue
+				}
+				// The queries before this one that finished already exceed the limit, the rest
+				// can only add to them.
+				if exceeded(i) {
+					results[i].Error = s.batchTooLarge()
+					continue
+				}
+				resp, errResp := s.List(ctx, queries[i].PtListRequest, queries[i].Options()...)
+				if resp != nil {
+					mu.Lock()
+					entries[i] = int64(len(resp.Timestamps) + len(resp.Intervals))
+					mu.Unlock()
+				}
+				results[i].Result, results[i].Error = resp, errResp
+			}
+		}()
+	}
+
+	for i := range queries {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	// Reserve the limit in query order.
+	var total int64
+	for i := range results {
+		total += entries[i]
+		if total > int64(s.batchEntries) && results[i].Result != nil {
+			results[i].Result, results[i].Error = nil, s.batchTooLarge()
+		}
+	}
+
+	return results, nil
+}
+
+// batchTooLarge reports a batch whose results exceed the batch entry limit.
+func (s *Service) batchTooLarge() *errors.ErrResp {
+	errResp := errors.GetError(errors.ResultTooLarge)
+	errResp.Desc = fmt.Sprintf("%s: a batch lists at most %d timestamps", errResp.Desc, s.batchEntries)
+	return errResp
+}
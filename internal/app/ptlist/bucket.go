@@ -0,0 +1,223 @@
+package ptlist
+
+import (
+	"context"
+	"fmt"
+	"plist/errors"
+	"plist/utils"
+	"sort"
+	"time"
+)
+
+// DefaultMaxPoints is the default maximum number of timestamps or points in a request body.
+const DefaultMaxPoints = 100000
+
+// WithMaxPoints sets the maximum number of timestamps or points in a request body, bodies with
+// more are rejected.
+func WithMaxPoints(max int) ServiceOption {
+	return func(s *Service) {
+		s.maxPoints = max
+	}
+}
+
+// Bucket maps timestamps to the periods they fall in: the interval GetPtList lists in the intervals
+// mode from the last timestamp at or before each of them. Buckets follow the period boundaries of
+// GetPtList across daylight saving transitions as well, so timestamps inside a period the DST
+// policy skips get no interval.
+func (s *Service) Bucket(ctx context.Context, period, tz string, timestamps []string, opts ...Option) (*BucketResponse, *errors.ErrResp) {
+	o := newOptions(opts)
+
+	q, errResps := s.parseSchedule(PtListRequest{Period: period, TZ: tz}, o)
+	format, errResp := utils.ParseOutputFormat(o.output)
+	if errResp != nil {
+		errResp.Param = "output_format"
+		errResps = append(errResps, errResp)
+	}
+	timeObjs, timeErrResps := s.parseTimes(timestamps, q.loc, q.calendar, q.inputFormat)
+	errResps = append(errResps, timeErrResps...)
+	if len(errResps) > 0 {
+		return nil, errors.Join(errResps)
+	}
+	p, policy, loc := *q.period, q.policy, q.loc
+
+	b := &bucketer{
+		period: p,
+		policy: policy,
+		loc:    loc,
+	}
+
+	layout := labelLayout(p)
+	resp := &BucketResponse{
+		Buckets: make([]Bucket, len(timeObjs)),
+	}
+	for i, timeObj := range timeObjs {
+		if err := ctx.Err(); err != nil {
+			return nil, errors.Wrap(errors.RequestCanceled, err)
+		}
+
+		start, end, errResp := b.bucket(timeObj)
+		if errResp != nil {
+			return nil, errResp
+		}
+		resp.Buckets[i] = Bucket{
+			Timestamp: utils.FormatTime(timeObj, format, loc),
+		}
+		if !start.IsZero() {
+			resp.Buckets[i].Interval = &Interval{
+				Start: utils.FormatTime(start, format, loc),
+				End:   utils.FormatTime(end, format, loc),
+				Label: start.In(loc).Format(layout),
+			}
+		}
+	}
+
+	return resp, nil
+}
+
+// bucketer finds the periods of timestamps, it keeps the last one as consecutive timestamps
+// mostly share it. Away from offset transitions a period starts and ends at its wall-clock
+// boundaries in the offset of the timestamp, around them the timestamps of a window are walked
+// once and kept for the timestamps that follow.
+type bucketer struct {
+	period utils.Period
+	policy utils.DSTPolicy
+	loc    *time.Location
+	start  time.Time
+	end    time.Time
+
+	// walked holds the timestamps from far enough before the window to the end of the window.
+	walked      []time.Time
+	windowStart time.Time
+	windowEnd   time.Time
+}
+
+// bucket returns the start and end of the period a timestamp falls in, or zero times when it falls
+// in none.
+func (b *bucketer) bucket(timeObj time.Time) (time.Time, time.Time, *errors.ErrResp) {
+	if !timeObj.Before(b.start) && timeObj.Before(b.end) {
+		return b.start, b.end, nil
+	}
+
+	start, next, ok, errResp := b.bounds(timeObj)
+	if errResp != nil {
+		return time.Time{}, time.Time{}, errResp
+	}
+	if !ok {
+		return time.Time{}, time.Time{}, nil
+	}
+	end, errResp := utils.PeriodEnd(start, b.loc, b.period)
+	if errResp != nil {
+		return time.Time{}, time.Time{}, errResp
+	}
+	if !next.IsZero() && next.Before(end) {
+		end = next
+	}
+
+	b.start, b.end = start, end
+	if !timeObj.Before(end) {
+		return time.Time{}, time.Time{}, nil
+	}
+	return start, end, nil
+}
+
+// bounds returns the last timestamp at or before a time point and the next one, zero if none. It
+// reports whether there is a last timestamp.
+func (b *bucketer) bounds(timeObj time.Time) (time.Time, time.Time, bool, *errors.ErrResp) {
+	// Round on the local wall clock.
+	local := timeObj.In(b.loc)
+	floor := utils.WallClock(local)
+	ceil := floor
+	if errResp := utils.Round(&ceil, &floor, b.period); errResp != nil {
+		return time.Time{}, time.Time{}, false, errResp
+	}
+	next := floor
+	if errResp := utils.AddPeriod(&next, b.period); errResp != nil {
+		return time.Time{}, time.Time{}, false, errResp
+	}
+
+	// A single offset around the period maps its boundaries to single timestamps.
+	_, offset := local.Zone()
+	start := floor.Add(-time.Duration(offset) * time.Second)
+	end := next.Add(-time.Duration(offset) * time.Second)
+	zoneStart, zoneEnd := local.ZoneBounds()
+	single := (zoneStart.IsZero() || !start.Add(-transitionMargin).Before(zoneStart)) && (zoneEnd.IsZero() || end.Add(transitionMargin).Before(zoneEnd))
+	if single && !floor.Before(minWallClock) {
+		return start, end, true, nil
+	}
+
+	if timeObj.Before(b.windowStart) || !timeObj.Before(b.windowEnd) {
+		if errResp := b.walk(timeObj); errResp != nil {
+			return time.Time{}, time.Time{}, false, errResp
+		}
+	}
+	i := sort.Search(len(b.walked), func(i int) bool {
+		return b.walked[i].After(timeObj)
+	})
+	// The last timestamp must follow the start of the walk by prevMargin and the next one must
+	// precede its end by a day, so that no timestamp outside the walk falls between them.
+	if i > 0 && i < len(b.walked) && !b.walked[i-1].Before(b.windowStart.Add(-prevMargin)) && b.walked[i].Before(b.windowEnd.Add(prevMargin-24*time.Hour)) {
+		return b.walked[i-1], b.walked[i], true, nil
+	}
+
+	// Long periods start or end outside the walked timestamps, these are searched one at a time.
+	start, ok, errResp := lastTimestamps(b.period, b.policy, b.loc, timeObj, 1)
+	if errResp != nil || !ok {
+		return time.Time{}, time.Time{}, false, errResp
+	}
+	next, errResp = firstTimestamps(b.period, b.policy, b.loc, timeObj.Add(time.Nanosecond), 1)
+	if errResp != nil {
+		return time.Time{}, time.Time{}, false, errResp
+	}
+	return start, next, true, nil
+}
+
+// walk keeps the timestamps around a window of transitionMargin on each side of a time point. The
+// walk starts 2 prevMargin before the window, so that transitions before the timestamps of the
+// window and their previous ones cannot change them, and ends prevMargin after it.
+func (b *bucketer) walk(timeObj time.Time) *errors.ErrResp {
+	b.windowStart = timeObj.Add(-transitionMargin)
+	b.windowEnd = timeObj.Add(transitionMargin)
+
+	from := utils.FirstWallClock(b.windowStart.Add(-2*prevMargin), b.loc)
+	to := utils.LastWallClock(b.windowEnd.Add(prevMargin), b.loc)
+	if errResp := utils.Round(&from, &to, b.period); errResp != nil {
+		return errResp
+	}
+
+	b.walked = b.walked[:0]
+	return walkTimestamps(b.period, b.policy, b.loc, from, to, func(timeObj time.Time) bool {
+		b.walked = append(b.walked, timeObj)
+		return true
+	})
+}
+
+// parseTimes parses the timestamps of a request body in UTC, reporting every invalid one.
+func (s *Service) parseTimes(timestamps []string, loc *time.Location, calendar utils.Calendar, inputFormat string) ([]time.Time, []*errors.ErrResp) {
+	if errResp := s.checkPoints(len(timestamps), "timestamps"); errResp != nil {
+		return nil, []*errors.ErrResp{errResp}
+	}
+
+	timeObjs := make([]time.Time, len(timestamps))
+	var errResps []*errors.ErrResp
+	for i, timestamp := range timestamps {
+		timeObj, errResp := s.parseTime(timestamp, loc, calendar, inputFormat)
+		if errResp != nil {
+			errResp.Param = fmt.Sprintf("timestamps[%d]", i)
+			errResps = append(errResps, errResp)
+			continue
+		}
+		timeObjs[i] = timeObj
+	}
+	return timeObjs, errResps
+}
+
+// checkPoints rejects request bodies with more timestamps or points than the service maximum.
+func (s *Service) checkPoints(n int, param string) *errors.ErrResp {
+	if n <= s.maxPoints {
+		return nil
+	}
+	errResp := errors.GetError(errors.BodyTooLarge)
+	errResp.Desc = fmt.Sprintf("%s: at most %d %s", errResp.Desc, s.maxPoints, param)
+	errResp.Param = param
+	return errResp
+}
@@ -0,0 +1,25 @@
+package ptlist
+
+import "time"
+
+// Clock provides the current time to the service.
+type Clock interface {
+	Now() time.Time
+}
+
+// realClock reads the system clock.
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// ServiceOption configures the service.
+type ServiceOption func(*Service)
+
+// WithClock replaces the system clock of the service, e.g. with a fixed clock in tests.
+func WithClock(clock Clock) ServiceOption {
+	return func(s *Service) {
+		s.clock = clock
+	}
+}
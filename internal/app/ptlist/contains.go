@@ -0,0 +1,55 @@
+package ptlist
+
+import (
+	"context"
+	"plist/errors"
+	"plist/utils"
+	"time"
+)
+
+// Contains tells whether a time point, now when empty, is a timestamp GetPtList lists for a
+// period, and returns the nearest timestamps strictly before and after it in the output format.
+// Timestamps follow the rounding of GetPtList, so a time point is contained exactly when any range
+// around it lists it.
+func (s *Service) Contains(ctx context.Context, period, tz, ts string, opts ...Option) (*ContainsResponse, *errors.ErrResp) {
+	o := newOptions(opts)
+
+	q, errResps := s.parsePoint(o, PtListRequest{Period: period, TZ: tz, T1: ts}, "ts")
+	format, errResp := utils.ParseOutputFormat(o.output)
+	if errResp != nil {
+		errResp.Param = "output_format"
+		errResps = append(errResps, errResp)
+	}
+	if len(errResps) > 0 {
+		return nil, errors.Join(errResps)
+	}
+	p, policy, loc, tsUTC := *q.period, q.policy, q.loc, q.timeObj1UTC
+
+	next, errResp := firstTimestamps(p, policy, loc, tsUTC, 1)
+	if errResp != nil {
+		return nil, errResp
+	}
+	contains := next.Equal(tsUTC)
+	if contains {
+		next, errResp = firstTimestamps(p, policy, loc, tsUTC.Add(time.Nanosecond), 1)
+		if errResp != nil {
+			return nil, errResp
+		}
+	}
+
+	prev, ok, errResp := lastTimestamps(p, policy, loc, tsUTC.Add(-time.Nanosecond), 1)
+	if errResp != nil {
+		return nil, errResp
+	}
+
+	resp := &ContainsResponse{
+		Contains: contains,
+	}
+	if ok {
+		resp.Prev = utils.FormatTime(prev, format, loc)
+	}
+	if !next.IsZero() {
+		resp.Next = utils.FormatTime(next, format, loc)
+	}
+	return resp, nil
+}
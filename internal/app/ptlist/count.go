@@ -0,0 +1,179 @@
+package ptlist
+
+import (
+	"context"
+	"plist/errors"
+	"plist/utils"
+	"time"
+)
+
+// fixedSteps are the wall-clock lengths, in seconds, of the period units that do not depend on
+// the calendar.
+var fixedSteps = map[string]int64{
+	utils.Minute: 60,
+	utils.Hour:   60 * 60,
+	utils.Day:    24 * 60 * 60,
+	utils.Week:   7 * 24 * 60 * 60,
+}
+
+// transitionMargin is how far, on the wall clock, a daylight saving or other offset transition
+// may affect the timestamps around it: the day of the transition and the day around the range ends.
+const transitionMargin = 2 * 24 * time.Hour
+
+// Count returns the number of timestamps GetPtList lists between 2 time points, without listing
+// them. Minute, hour, day and week periods are counted in closed form, apart from the days around
+// offset transitions of the timezone, while month, quarter and year periods walk their boundaries.
+// The request is validated like a list, all field errors together, but ranges are not limited.
+func (s *Service) Count(ctx context.Context, period, tz, t1, t2 string, opts ...Option) (*CountResponse, *errors.ErrResp) {
+	o := newOptions(opts)
+
+	q, errResp := s.validate(PtListRequest{Period: period, TZ: tz, T1: t1, T2: t2}, o, 0)
+	if errResp != nil {
+		return nil, errResp
+	}
+	p, policy, loc, timeObj1UTC, timeObj2UTC := *q.period, q.policy, q.loc, q.timeObj1UTC, q.timeObj2UTC
+
+	// Round on the local wall clock.
+	wallClock1 := utils.FirstWallClock(timeObj1UTC, loc)
+	wallClock2 := utils.LastWallClock(timeObj2UTC, loc)
+	errResp = utils.Round(&wallClock1, &wallClock2, p)
+	if errResp != nil {
+		return nil, errResp
+	}
+
+	c := &counter{
+		period:      p,
+		policy:      policy,
+		loc:         loc,
+		timeObj1UTC: timeObj1UTC,
+		timeObj2UTC: timeObj2UTC,
+	}
+
+	var count int
+	if step, ok := fixedSteps[p.Unit]; ok {
+		count, errResp = c.countFixed(wallClock1, wallClock2, int64(p.N)*step)
+	} else {
+		count, _, errResp = c.walk(wallClock1, wallClock2, time.Time{})
+	}
+	if errResp != nil {
+		return nil, errResp
+	}
+
+	return &CountResponse{
+		Count: count,
+	}, nil
+}
+
+// counter counts the timestamps of a period list.
+type counter struct {
+	period      utils.Period
+	policy      utils.DSTPolicy
+	loc         *time.Location
+	timeObj1UTC time.Time
+	timeObj2UTC time.Time
+}
+
+// walk counts the timestamps of the boundaries from one wall-clock boundary to another, both
+// inclusive, that follow the last timestamp. It also returns the last counted timestamp.
+func (c *counter) walk(from, to, last time.Time) (int, time.Time, *errors.ErrResp) {
+	var count int
+	add := func(timeObj time.Time) {
+		if !timeObj.Before(c.timeObj1UTC) && !timeObj.After(c.timeObj2UTC) && follows(timeObj, last) {
+			count++
+			last = timeObj
+		}
+	}
+
+	timeline := utils.NewTimeline(c.loc, c.policy)
+	for wallClock := from; !wallClock.After(to); {
+		timeline.Add(wallClock, add)
+
+		if errResp := utils.AddPeriod(&wallClock, c.period); errResp != nil {
+			return 0, last, errResp
+		}
+	}
+	timeline.Flush(add)
+	return count, last, nil
+}
+
+// countFixed counts the timestamps of the boundaries from one wall-clock boundary to another that
+// are a fixed step of seconds apart. Away from offset transitions each boundary is a single
+// timestamp in the range, the boundaries around transitions are walked instead. Boundaries are
+// counted in whole seconds, time.Duration saturates after 292 years.
+func (c *counter) countFixed(from, to time.Time, step int64) (int, *errors.ErrResp) {
+	if from.After(to) {
+		return 0, nil
+	}
+
+	boundary := func(k int64) time.Time {
+		return time.Unix(from.Unix()+k*step, 0).UTC()
+	}
+	last := utils.FloorDiv(to.Unix()-from.Unix(), step)
+	count := int(last) + 1
+
+	for _, window := range c.transitionWindows() {
+		k1 := utils.CeilDiv(window[0].Unix()-from.Unix(), step)
+		k2 := utils.FloorDiv(window[1].Unix()-from.Unix(), step)
+		if k1 < 0 {
+			k1 = 0
+		}
+		if k2 > last {
+			k2 = last
+		}
+		if k1 > k2 {
+			continue
+		}
+
+		// The boundary before the window is a single timestamp in the range.
+		var before time.Time
+		if k1 > 0 {
+			before = utils.Resolve(boundary(k1-1), c.loc, c.policy)[0].UTC()
+		}
+
+		n, _, errResp := c.walk(boundary(k1), boundary(k2), before)
+		if errResp != nil {
+			return 0, errResp
+		}
+		count += n - int(k2-k1+1)
+	}
+
+	return count, nil
+}
+
+// transitionWindows returns the merged wall-clock windows around the offset transitions of the
+// location near the range, in order.
+func (c *counter) transitionWindows() [][2]time.Time {
+	var windows [][2]time.Time
+
+	end := c.timeObj2UTC.Add(transitionMargin)
+	for t := c.timeObj1UTC.Add(-transitionMargin); ; {
+		_, next := t.In(c.loc).ZoneBounds()
+
+		// Around the end of some years past the transitions listed for a location, ZoneBounds
+		// ends the zone before the given time, these days are stepped over one at a time.
+		stalled := !next.IsZero() && !next.After(t)
+		if stalled {
+			next = t.Add(24 * time.Hour)
+		}
+		if next.IsZero() || next.After(end) {
+			break
+		}
+		_, offset := t.In(c.loc).Zone()
+		_, nextOffset := next.In(c.loc).Zone()
+		if stalled && offset == nextOffset {
+			t = next
+			continue
+		}
+
+		wallClock := utils.WallClock(next.In(c.loc))
+		window := [2]time.Time{wallClock.Add(-transitionMargin), wallClock.Add(transitionMargin)}
+		if n := len(windows); n > 0 && !window[0].After(windows[n-1][1]) {
+			windows[n-1][1] = window[1]
+		} else {
+			windows = append(windows, window)
+		}
+		t = next
+	}
+
+	return windows
+}
@@ -0,0 +1,195 @@
+package ptlist
+
+import (
+	"context"
+	"fmt"
+	"plist/errors"
+	"plist/utils"
+	"time"
+)
+
+// TimestampReader returns the next timestamp of a sequence, or false once the sequence ends.
+type TimestampReader func() (string, bool, error)
+
+// ReadSlice returns a reader of the given timestamps.
+func ReadSlice(timestamps []string) TimestampReader {
+	var i int
+	return func() (string, bool, error) {
+		if i == len(timestamps) {
+			return "", false, nil
+		}
+		i++
+		return timestamps[i-1], true, nil
+	}
+}
+
+// Gaps validates a request and compares observed timestamps, in ascending order, with its schedule.
+// It emits through fn, in order, the timestamps of the schedule that were not observed, the
+// observed ones that are not on the schedule or outside its range and every repeated observation,
+// until fn returns false or the context is done. Both the schedule and the observed timestamps are
+// read as they are compared, so neither is held in memory.
+func (s *Service) Gaps(ctx context.Context, req PtListRequest, next TimestampReader, fn func(Gap) bool, opts ...Option) *errors.ErrResp {
+	if errResp := s.Validate(ctx, req, opts...); errResp != nil {
+		return errResp
+	}
+
+	o := newOptions(opts)
+	calendar, errResp := o.calendar()
+	if errResp != nil {
+		return errResp
+	}
+	loc, inputFormat, errResp := parseLocation(req.TZ, o.input)
+	if errResp != nil {
+		return errResp
+	}
+	format, errResp := utils.ParseOutputFormat(o.output)
+	if errResp != nil {
+		errResp.Param = "output_format"
+		return errResp
+	}
+
+	g := &gapFinder{
+		next: next,
+		parse: func(value string) (time.Time, *errors.ErrResp) {
+			return s.parseTime(value, loc, calendar, inputFormat)
+		},
+		loc:    loc,
+		format: format,
+		emit: func(gap Gap) bool {
+			return ctx.Err() == nil && fn(gap)
+		},
+	}
+	g.advance()
+
+	// The gap finder formats the timestamps of the schedule itself.
+	opts = append(opts, withInstants(g.expect), WithLocal(false), WithMode(ModePoints), WithLimit(""), WithCursor(""))
+	if _, errResp := s.schedule(ctx, req, nil, opts); errResp != nil {
+		return errResp
+	}
+	g.finish()
+
+	if g.err != nil {
+		return g.err
+	}
+	if err := ctx.Err(); err != nil {
+		return errors.Wrap(errors.RequestCanceled, err)
+	}
+	return nil
+}
+
+// GetGaps returns the differences Gaps finds between observed timestamps and the schedule of a request.
+func (s *Service) GetGaps(ctx context.Context, req PtListRequest, next TimestampReader, opts ...Option) (*GapsResponse, *errors.ErrResp) {
+	resp := &GapsResponse{
+		Missing:     []string{},
+		OffSchedule: []string{},
+		Duplicates:  []string{},
+	}
+	errResp := s.Gaps(ctx, req, next, func(gap Gap) bool {
+		switch gap.Kind {
+		case GapMissing:
+			resp.Missing = append(resp.Missing, gap.Timestamp)
+		case GapOffSchedule:
+			resp.OffSchedule = append(resp.OffSchedule, gap.Timestamp)
+		case GapDuplicate:
+			resp.Duplicates = append(resp.Duplicates, gap.Timestamp)
+		}
+		return true
+	}, opts...)
+	if errResp != nil {
+		return nil, errResp
+	}
+	return resp, nil
+}
+
+// gapFinder merges the observed timestamps into the schedule. It holds the next observed
+// timestamp that no expected one has reached yet.
+type gapFinder struct {
+	next   TimestampReader
+	parse  func(string) (time.Time, *errors.ErrResp)
+	loc    *time.Location
+	format string
+	emit   func(Gap) bool
+
+	observed time.Time
+	held     bool
+	count    int
+	done     bool
+	err      *errors.ErrResp
+}
+
+// expect compares an expected timestamp of the schedule with the observed timestamps up to it.
+func (g *gapFinder) expect(timeObj, _ time.Time, _ string) bool {
+	for g.held && g.observed.Before(timeObj) && !g.done {
+		g.report(GapOffSchedule, g.observed)
+		g.advance()
+	}
+	if g.held && g.observed.Equal(timeObj) {
+		g.advance()
+	} else {
+		g.report(GapMissing, timeObj)
+	}
+	return !g.done
+}
+
+// finish reports the observed timestamps after the schedule.
+func (g *gapFinder) finish() {
+	for g.held && !g.done {
+		g.report(GapOffSchedule, g.observed)
+		g.advance()
+	}
+}
+
+// advance reads the next observed timestamp, reporting the repeated ones.
+func (g *gapFinder) advance() {
+	g.held = false
+	for !g.done {
+		value, ok, err := g.next()
+		if err != nil {
+			errResp := errors.Wrap(errors.InvalidRequestBody, err)
+			errResp.Param = "timestamps"
+			g.fail(errResp)
+			return
+		}
+		if !ok {
+			return
+		}
+
+		param := fmt.Sprintf("timestamps[%d]", g.count)
+		g.count++
+		timeObj, errResp := g.parse(value)
+		if errResp != nil {
+			errResp.Param = param
+			g.fail(errResp)
+			return
+		}
+
+		timeObj = timeObj.UTC()
+		switch {
+		case g.count > 1 && timeObj.Before(g.observed):
+			errResp := errors.GetError(errors.UnsortedTimestamps)
+			errResp.Param = param
+			g.fail(errResp)
+			return
+		case g.count > 1 && timeObj.Equal(g.observed):
+			g.report(GapDuplicate, timeObj)
+			continue
+		}
+
+		g.observed = timeObj
+		g.held = true
+		return
+	}
+}
+
+// report emits a gap.
+func (g *gapFinder) report(kind string, timeObj time.Time) {
+	if !g.emit(Gap{Kind: kind, Timestamp: utils.FormatTime(timeObj, g.format, g.loc)}) {
+		g.done = true
+	}
+}
+
+// fail stops the gap finder with an error.
+func (g *gapFinder) fail(errResp *errors.ErrResp) {
+	g.err = errResp
+	g.done = true
+}
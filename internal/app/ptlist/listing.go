@@ -0,0 +1,256 @@
+package ptlist
+
+import (
+	"context"
+	"plist/errors"
+	"plist/utils"
+	"time"
+)
+
+// Output modes.
+const (
+	ModePoints    = "points"
+	ModeIntervals = "intervals"
+)
+
+// labelLayouts are the local layouts of interval labels per period unit.
+var labelLayouts = map[string]string{
+	utils.Minute:  "2006-01-02T15:04-07:00",
+	utils.Hour:    "2006-01-02T15:04-07:00",
+	utils.Day:     "2006-01-02",
+	utils.Week:    "2006-01-02",
+	utils.Month:   "2006-01",
+	utils.Quarter: "2006-01",
+	utils.Year:    "2006-01",
+}
+
+// listing emits the timestamps of a request in order, in its output format. Without an emit
+// callback it collects them in its response.
+type listing struct {
+	ctx         context.Context
+	loc         *time.Location
+	timeObj1UTC time.Time
+	timeObj2UTC time.Time
+	format      string
+	local       bool
+	last        time.Time
+	key         uint64
+	emit        func(Entry) bool
+	instants    func(start, end time.Time, label string) bool
+	resp        *PtListResponse
+
+	// In the intervals mode each interval is pending until the next timestamp ends it, or the end
+	// of its period if earlier, e.g. when the dst policy skips the next period.
+	intervals    bool
+	period       utils.Period
+	labelLayout  string
+	pending      bool
+	pendingStart time.Time
+	pendingEnd   time.Time
+
+	// The listing is done once it holds limit entries and finds another one, once emit returns
+	// false, once it collects more than maxLimit entries without a limit or once the context is
+	// done, which fail it with err.
+	limit    int
+	maxLimit int
+	count    int
+	done     bool
+	err      *errors.ErrResp
+}
+
+// listing returns an empty listing of the time points between t1 and t2, both inclusive, of the
+// query with the given key. It starts after the cursor option if given and stops once the context
+// is done. Only period lists, with a non nil period, support the
+// intervals mode. A nil emit callback collects the entries in the response of the listing, unless
+// the instants option is given.
+func (o *options) listing(ctx context.Context, key uint64, loc *time.Location, timeObj1UTC, timeObj2UTC time.Time, period *utils.Period, maxLimit int, emit func(Entry) bool) (*listing, *errors.ErrResp) {
+	format, errResp := utils.ParseOutputFormat(o.output)
+	if errResp != nil {
+		errResp.Param = "output_format"
+		return nil, errResp
+	}
+
+	limit, cursor, errResp := o.page(maxLimit, key)
+	if errResp != nil {
+		return nil, errResp
+	}
+
+	l := &listing{
+		ctx:         ctx,
+		loc:         loc,
+		timeObj1UTC: timeObj1UTC,
+		timeObj2UTC: timeObj2UTC,
+		format:      format,
+		local:       o.local,
+		emit:        emit,
+		instants:    o.instants,
+		last:        cursor,
+		key:         key,
+		limit:       limit,
+		maxLimit:    maxLimit,
+	}
+
+	switch o.mode {
+	case "", ModePoints:
+	case ModeIntervals:
+		if period == nil {
+			return nil, modeError()
+		}
+		if l.local {
+			errResp := errors.GetError(errors.UnsupportedMode)
+			errResp.Param = "local"
+			return nil, errResp
+		}
+		l.intervals = true
+		l.period = *period
+		l.labelLayout = labelLayout(*period)
+	default:
+		return nil, modeError()
+	}
+
+	if emit == nil && o.instants == nil {
+		l.resp = &PtListResponse{}
+		switch {
+		case l.intervals:
+			l.resp.Intervals = []Interval{}
+		case l.local:
+			l.resp.Timestamps = []string{}
+			l.resp.Local = []string{}
+		default:
+			l.resp.Timestamps = []string{}
+		}
+		l.emit = l.collect
+	}
+
+	return l, nil
+}
+
+// labelLayout returns the local layout of the interval labels of a period.
+func labelLayout(p utils.Period) string {
+	if p.Unit == utils.Year && p.YearStart == time.January {
+		return "2006"
+	}
+	return labelLayouts[p.Unit]
+}
+
+// modeError reports an unsupported output mode.
+func modeError() *errors.ErrResp {
+	errResp := errors.GetError(errors.UnsupportedMode)
+	errResp.Param = "mode"
+	return errResp
+}
+
+// add lists a time point. Wall-clock times around daylight saving transitions may land outside
+// the requested range or on an already listed timestamp, these are left out. In the intervals
+// mode the first time point after the range still ends the last interval.
+func (l *listing) add(timeObj time.Time) {
+	timeObj = timeObj.UTC()
+	if l.done || timeObj.Before(l.timeObj1UTC) || !follows(timeObj, l.last) {
+		return
+	}
+	if err := l.ctx.Err(); err != nil {
+		l.err = errors.Wrap(errors.RequestCanceled, err)
+		l.done = true
+		return
+	}
+
+	if l.pending {
+		end := timeObj
+		if l.pendingEnd.Before(end) {
+			end = l.pendingEnd
+		}
+		l.pending = false
+		if !l.emitInstants(l.pendingStart, end) {
+			l.done = true
+			return
+		}
+	}
+	if timeObj.After(l.timeObj2UTC) {
+		return
+	}
+
+	switch {
+	case l.limit > 0 && l.count == l.limit:
+		if l.emit != nil {
+			l.emit(Entry{NextCursor: encodeCursor(l.last, l.key)})
+		}
+		l.done = true
+		return
+	case l.limit == 0 && l.resp != nil && l.count == l.maxLimit:
+		l.err = errors.GetError(errors.ResultTooLarge)
+		l.err.Param = "limit"
+		l.done = true
+		return
+	}
+	l.count++
+
+	l.last = timeObj
+	if l.intervals {
+		end, errResp := utils.PeriodEnd(timeObj, l.loc, l.period)
+		if errResp != nil {
+			l.err = errResp
+			l.done = true
+			return
+		}
+		l.pending, l.pendingStart, l.pendingEnd = true, timeObj, end
+		return
+	}
+
+	if !l.emitInstants(timeObj, time.Time{}) {
+		l.done = true
+	}
+}
+
+// emitInstants emits a timestamp, or an interval when the end is given, through the instants
+// callback if given or else as a formatted entry.
+func (l *listing) emitInstants(start, end time.Time) bool {
+	var label string
+	if l.intervals {
+		label = start.In(l.loc).Format(l.labelLayout)
+	}
+	if l.instants != nil {
+		return l.instants(start, end, label)
+	}
+
+	if l.intervals {
+		return l.emit(Entry{Interval: &Interval{
+			Start: utils.FormatTime(start, l.format, l.loc),
+			End:   utils.FormatTime(end, l.format, l.loc),
+			Label: label,
+		}})
+	}
+	entry := Entry{Timestamp: utils.FormatTime(start, l.format, l.loc)}
+	if l.local {
+		entry.Local = utils.FormatTime(start, utils.OutputRFC3339Local, l.loc)
+	}
+	return l.emit(entry)
+}
+
+// collect adds an entry to the response of the listing.
+func (l *listing) collect(entry Entry) bool {
+	switch {
+	case entry.Interval != nil:
+		l.resp.Intervals = append(l.resp.Intervals, *entry.Interval)
+	case entry.NextCursor != "":
+		l.resp.NextCursor = entry.NextCursor
+	default:
+		l.resp.Timestamps = append(l.resp.Timestamps, entry.Timestamp)
+		if l.local {
+			l.resp.Local = append(l.resp.Local, entry.Local)
+		}
+	}
+	return true
+}
+
+// open reports whether the last interval still waits for its end.
+func (l *listing) open() bool {
+	return l.pending
+}
+
+// start returns the first instant the listing may hold, t1 or the cursor if later.
+func (l *listing) start() time.Time {
+	if l.last.After(l.timeObj1UTC) {
+		return l.last
+	}
+	return l.timeObj1UTC
+}
@@ -1,6 +1,185 @@
 package ptlist
 
+import "plist/errors"
+
+// PtListRequest holds the schedule and time range of a single list. The schedule is the
+// recurrence rule if given, else the cron expression if given, else the period.
+type PtListRequest struct {
+	Period string `json:"period,omitempty"`
+	RRule  string `json:"rrule,omitempty"`
+	Cron   string `json:"cron,omitempty"`
+	TZ     string `json:"tz"`
+	T1     string `json:"t1"`
+	T2     string `json:"t2"`
+}
+
 // PtLists struct.
 type PtListResponse struct {
 	Timestamps []string `json:"timestamps,omitempty"`
+
+	// Local holds the local RFC 3339 representation of each timestamp when requested.
+	Local []string `json:"local,omitempty"`
+
+	// Intervals holds the periods starting at each timestamp in the intervals mode.
+	Intervals []Interval `json:"intervals,omitempty"`
+
+	// NextCursor continues the list on the next page when it holds more entries than the limit.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// CountResponse holds the number of timestamps of a list.
+type CountResponse struct {
+	Count int `json:"count"`
+}
+
+// Interval is a single period, from its start up to its end exclusive.
+type Interval struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+	Label string `json:"label"`
+}
+
+// ContainsResponse tells whether a time point is a timestamp of a period list, along with the
+// nearest timestamps before and after it, empty when there are none.
+type ContainsResponse struct {
+	Contains bool   `json:"contains"`
+	Prev     string `json:"prev,omitempty"`
+	Next     string `json:"next,omitempty"`
+}
+
+// PeriodOptions are the options of the period requests with a JSON body.
+type PeriodOptions struct {
+	WeekStart    string `json:"week_start,omitempty"`
+	YearStart    string `json:"year_start,omitempty"`
+	DST          string `json:"dst,omitempty"`
+	InputFormat  string `json:"input_format,omitempty"`
+	OutputFormat string `json:"output_format,omitempty"`
+}
+
+// BucketRequest is the body of a bucket request: the timestamps to bucket by a period.
+type BucketRequest struct {
+	Period     string   `json:"period"`
+	TZ         string   `json:"tz"`
+	Timestamps []string `json:"timestamps"`
+	PeriodOptions
+}
+
+// BucketResponse holds the bucket of each timestamp of a bucket request, in order.
+type BucketResponse struct {
+	Buckets []Bucket `json:"buckets"`
+}
+
+// Bucket is the period a timestamp falls in, without an interval when it falls in none.
+type Bucket struct {
+	Timestamp string `json:"timestamp"`
+	*Interval
+}
+
+// Gap kinds.
+const (
+	GapMissing     = "missing"
+	GapOffSchedule = "off_schedule"
+	GapDuplicate   = "duplicate"
+)
+
+// Gap is a single difference between observed timestamps and a schedule.
+type Gap struct {
+	Kind      string `json:"kind"`
+	Timestamp string `json:"timestamp"`
+}
+
+// GapsResponse holds the differences between observed timestamps and a schedule, each in order.
+type GapsResponse struct {
+	Missing     []string `json:"missing"`
+	OffSchedule []string `json:"off_schedule"`
+	Duplicates  []string `json:"duplicates"`
+}
+
+// Point is a single value of a time series.
+type Point struct {
+	Timestamp string  `json:"timestamp"`
+	Value     float64 `json:"value"`
+}
+
+// AggregateRequest is the body of an aggregate request: the points to aggregate into the
+// periods of a range.
+type AggregateRequest struct {
+	Period string  `json:"period"`
+	TZ     string  `json:"tz"`
+	T1     string  `json:"t1"`
+	T2     string  `json:"t2"`
+	Points []Point `json:"points"`
+	Fill   string  `json:"fill,omitempty"`
+	PeriodOptions
+}
+
+// AggregateResponse holds the aggregate of each period of a range, in order.
+type AggregateResponse struct {
+	Periods []Aggregate `json:"periods"`
+}
+
+// Aggregate holds the statistics of the values in a period. Values are null in empty periods,
+// unless a fill policy replaces them.
+type Aggregate struct {
+	Interval
+	Count int      `json:"count"`
+	Sum   *float64 `json:"sum"`
+	Avg   *float64 `json:"avg"`
+	Min   *float64 `json:"min"`
+	Max   *float64 `json:"max"`
+	First *float64 `json:"first"`
+	Last  *float64 `json:"last"`
+}
+
+// ResampleRequest is the body of a resample request: the points to resample onto the timestamps
+// of a range.
+type ResampleRequest struct {
+	Period string  `json:"period"`
+	TZ     string  `json:"tz"`
+	T1     string  `json:"t1"`
+	T2     string  `json:"t2"`
+	Points []Point `json:"points"`
+	Method string  `json:"method,omitempty"`
+	MaxGap string  `json:"max_gap,omitempty"`
+	PeriodOptions
+}
+
+// ResampleResponse holds the resampled value at each timestamp of a range, in order.
+type ResampleResponse struct {
+	Samples []Sample `json:"samples"`
+}
+
+// Sample is a single resampled value, null where the points leave it undefined.
+type Sample struct {
+	Timestamp string   `json:"timestamp"`
+	Value     *float64 `json:"value"`
+}
+
+// Entry is a single timestamp, with its local representation when requested, or a single
+// interval in the intervals mode. The last entry of a page holds only the next cursor.
+type Entry struct {
+	Timestamp string `json:"timestamp,omitempty"`
+	Local     string `json:"local,omitempty"`
+	*Interval
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// BatchQuery is a single query of a batch: a request along with its options.
+type BatchQuery struct {
+	PtListRequest
+	WeekStart    string `json:"week_start,omitempty"`
+	YearStart    string `json:"year_start,omitempty"`
+	DST          string `json:"dst,omitempty"`
+	InputFormat  string `json:"input_format,omitempty"`
+	OutputFormat string `json:"output_format,omitempty"`
+	Local        bool   `json:"local,omitempty"`
+	Mode         string `json:"mode,omitempty"`
+	Limit        int    `json:"limit,omitempty"`
+	Cursor       string `json:"cursor,omitempty"`
+}
+
+// BatchResult holds either the list or the error of a batch query.
+type BatchResult struct {
+	Result *PtListResponse `json:"result,omitempty"`
+	Error  *errors.ErrResp `json:"error,omitempty"`
 }
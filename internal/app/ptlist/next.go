@@ -0,0 +1,222 @@
+package ptlist
+
+import (
+	"context"
+	"fmt"
+	"plist/errors"
+	"plist/utils"
+	"time"
+)
+
+// prevMargin is how far before the previous occurrences, on the wall clock, their search starts
+// walking, so that daylight saving transitions before them cannot change them.
+const prevMargin = 2 * 24 * time.Hour
+
+// Next returns the next n timestamps of a period after a time point, now when empty, in the
+// form and with the options of GetPtList.
+func (s *Service) Next(ctx context.Context, period, tz, from string, n int, opts ...Option) (*PtListResponse, *errors.ErrResp) {
+	o := newOptions(opts)
+
+	q, errResp := s.parseFrom(o, period, tz, from, n)
+	if errResp != nil {
+		return nil, errResp
+	}
+	p, policy, loc, fromUTC := *q.period, q.policy, q.loc, q.timeObj1UTC
+
+	timeObj1UTC := fromUTC.Add(time.Nanosecond)
+	timeObj2UTC, errResp := firstTimestamps(p, policy, loc, timeObj1UTC, n)
+	if errResp != nil {
+		return nil, errResp
+	}
+
+	l, errResp := s.periodList(ctx, o, q.key, p, policy, loc, timeObj1UTC, timeObj2UTC, nil)
+	if errResp != nil {
+		return nil, errResp
+	}
+	return l.resp, nil
+}
+
+// Prev returns the previous n timestamps of a period before a time point, now when empty, in
+// order and in the form and with the options of GetPtList.
+func (s *Service) Prev(ctx context.Context, period, tz, from string, n int, opts ...Option) (*PtListResponse, *errors.ErrResp) {
+	o := newOptions(opts)
+
+	q, errResp := s.parseFrom(o, period, tz, from, n)
+	if errResp != nil {
+		return nil, errResp
+	}
+	p, policy, loc, fromUTC := *q.period, q.policy, q.loc, q.timeObj1UTC
+
+	timeObj2UTC := fromUTC.Add(-time.Nanosecond)
+	timeObj1UTC, ok, errResp := lastTimestamps(p, policy, loc, timeObj2UTC, n)
+	if errResp != nil {
+		return nil, errResp
+	}
+	if !ok {
+		// No timestamps, list the empty range.
+		timeObj1UTC = fromUTC
+	}
+
+	l, errResp := s.periodList(ctx, o, q.key, p, policy, loc, timeObj1UTC, timeObj2UTC, nil)
+	if errResp != nil {
+		return nil, errResp
+	}
+	return l.resp, nil
+}
+
+// firstTimestamps returns the last of the first n timestamps of a period at or after a time point,
+// or of as many as there are before maxWallClock. It returns the zero time when there are none.
+func firstTimestamps(p utils.Period, policy utils.DSTPolicy, loc *time.Location, timeObj1UTC time.Time, n int) (time.Time, *errors.ErrResp) {
+	// Round on the local wall clock.
+	wallClock1 := utils.FirstWallClock(timeObj1UTC, loc)
+	wallClock2 := wallClock1
+	errResp := utils.Round(&wallClock1, &wallClock2, p)
+	if errResp != nil {
+		return time.Time{}, errResp
+	}
+
+	var timeObj2UTC time.Time
+	count := 0
+	errResp = walkTimestamps(p, policy, loc, wallClock1, maxWallClock, func(timeObj time.Time) bool {
+		if timeObj.Before(timeObj1UTC) {
+			return true
+		}
+		count++
+		timeObj2UTC = timeObj
+		return count < n
+	})
+	if errResp != nil {
+		return time.Time{}, errResp
+	}
+	return timeObj2UTC, nil
+}
+
+// lastTimestamps returns the first of the last n timestamps of a period at or before a time point,
+// or of as many as there are after minWallClock. It reports whether there are any.
+func lastTimestamps(p utils.Period, policy utils.DSTPolicy, loc *time.Location, timeObj2UTC time.Time, n int) (time.Time, bool, *errors.ErrResp) {
+	// Round on the local wall clock.
+	wallClock2 := utils.LastWallClock(timeObj2UTC, loc)
+	wallClock1 := wallClock2
+	errResp := utils.Round(&wallClock1, &wallClock2, p)
+	if errResp != nil {
+		return time.Time{}, false, errResp
+	}
+
+	// Timestamps are only defined walking forward, so walk forward from further and further back
+	// until the walk finds n timestamps or starts at minWallClock.
+	for k := n; ; k *= 2 {
+		back := p
+		back.N = -k * p.N
+		start := wallClock2
+		errResp = utils.AddPeriod(&start, back)
+		if errResp != nil {
+			return time.Time{}, false, errResp
+		}
+		start = start.Add(-prevMargin)
+		bounded := start.Before(minWallClock)
+		if bounded {
+			start = minWallClock
+		}
+		ceil := start
+		errResp = utils.Round(&ceil, &start, p)
+		if errResp != nil {
+			return time.Time{}, false, errResp
+		}
+
+		var timestamps []time.Time
+		errResp = walkTimestamps(p, policy, loc, start, wallClock2, func(timeObj time.Time) bool {
+			if !timeObj.After(timeObj2UTC) {
+				timestamps = append(timestamps, timeObj)
+			}
+			return true
+		})
+		if errResp != nil {
+			return time.Time{}, false, errResp
+		}
+
+		switch {
+		case len(timestamps) >= n:
+			return timestamps[len(timestamps)-n], true, nil
+		case bounded && len(timestamps) > 0:
+			return timestamps[0], true, nil
+		case bounded:
+			return time.Time{}, false, nil
+		}
+	}
+}
+
+// minWallClock and maxWallClock bound walks that stop on their own.
+var (
+	minWallClock = time.Date(1, time.January, 1, 0, 0, 0, 0, time.UTC)
+	maxWallClock = time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+)
+
+// walkTimestamps calls fn with the timestamps of the boundaries of a period from one wall-clock
+// boundary to another, both inclusive, in order as GetPtList lists them, until fn returns false.
+func walkTimestamps(p utils.Period, policy utils.DSTPolicy, loc *time.Location, from, to time.Time, fn func(time.Time) bool) *errors.ErrResp {
+	var last time.Time
+	var done bool
+	add := func(timeObj time.Time) {
+		if done || !follows(timeObj, last) {
+			return
+		}
+		last = timeObj
+		done = !fn(timeObj)
+	}
+
+	timeline := utils.NewTimeline(loc, policy)
+	for wallClock := from; !wallClock.After(to) && !done; {
+		timeline.Add(wallClock, add)
+
+		if errResp := utils.AddPeriod(&wallClock, p); errResp != nil {
+			return errResp
+		}
+	}
+	timeline.Flush(add)
+	return nil
+}
+
+// follows reports whether a timestamp follows the last one, any timestamp when there is none yet.
+// The zero time is the first instant of year 1 in UTC, a timestamp itself.
+func follows(timeObj, last time.Time) bool {
+	return last.IsZero() || timeObj.After(last)
+}
+
+// parseFrom parses the period, options, timezone, time point and number of occurrences of a next
+// or previous query, and reports every field error together.
+func (s *Service) parseFrom(o *options, period, tz, from string, n int) (*query, *errors.ErrResp) {
+	req := PtListRequest{Period: period, TZ: tz, T1: from}
+	q, errResps := s.parsePoint(o, req, "from")
+	errResps = append(errResps, s.checkOptions(req, o)...)
+
+	if n < 1 || n > s.maxLimit {
+		errResp := errors.GetError(errors.InvalidNumber)
+		errResp.Desc = fmt.Sprintf("%s: must be between 1 and %d", errResp.Desc, s.maxLimit)
+		errResp.Param = "n"
+		errResps = append(errResps, errResp)
+	}
+
+	if len(errResps) > 0 {
+		return nil, errors.Join(errResps)
+	}
+	return q, nil
+}
+
+// parsePoint parses the period, calendar, DST policy and input format options, timezone and time
+// point, now when empty, of a request whose single time point is T1. It reports every field error
+// and the parameter names the time point in them.
+func (s *Service) parsePoint(o *options, req PtListRequest, param string) (*query, []*errors.ErrResp) {
+	q, errResps := s.parseSchedule(req, o)
+
+	if req.T1 == "" {
+		q.timeObj1UTC = s.clock.Now().UTC()
+		return q, errResps
+	}
+	timeObj, errResp := s.parseTime(req.T1, q.loc, q.calendar, q.inputFormat)
+	if errResp != nil {
+		errResp.Param = param
+		errResps = append(errResps, errResp)
+	}
+	q.timeObj1UTC = timeObj
+	return q, errResps
+}
@@ -0,0 +1,220 @@
+package ptlist
+
+import (
+	"plist/errors"
+	"plist/utils"
+	"strconv"
+	"time"
+)
+
+// Option configures a single ptlist request.
+type Option func(*options)
+
+type options struct {
+	weekStart string
+	yearStart string
+	dst       string
+	input     string
+	output    string
+	local     bool
+	mode      string
+	limit     string
+	cursor    string
+	fill      string
+	method    string
+	maxGap    string
+
+	// instants receives the listed instants instead of their entries, see withInstants.
+	instants func(start, end time.Time, label string) bool
+}
+
+// WithWeekStart sets the first day of week periods, e.g. "sun" or "sunday". Monday is used when empty.
+func WithWeekStart(weekStart string) Option {
+	return func(o *options) {
+		o.weekStart = weekStart
+	}
+}
+
+// WithYearStart sets the first month of month, quarter and year periods, e.g. "04" for fiscal
+// years starting in April. January is used when empty.
+func WithYearStart(yearStart string) Option {
+	return func(o *options) {
+		o.yearStart = yearStart
+	}
+}
+
+// WithDST sets the policy for wall-clock times inside daylight saving gaps and overlaps:
+// skip, shift-forward, earliest, latest or both. Both is used when empty.
+func WithDST(policy string) Option {
+	return func(o *options) {
+		o.dst = policy
+	}
+}
+
+// WithInputFormat sets the format of both time points: compact, rfc3339, iso8601, date, unix or
+// unixms. The format is detected per time point when empty, which also accepts relative expressions.
+func WithInputFormat(format string) Option {
+	return func(o *options) {
+		o.input = format
+	}
+}
+
+// WithOutputFormat sets the format of the listed timestamps: compact, rfc3339, rfc3339local,
+// unix or unixms. The compact 20060102T150405Z format is used when empty.
+func WithOutputFormat(format string) Option {
+	return func(o *options) {
+		o.output = format
+	}
+}
+
+// WithLocal lists the local RFC 3339 representation of each timestamp next to it.
+func WithLocal(local bool) Option {
+	return func(o *options) {
+		o.local = local
+	}
+}
+
+// WithMode sets the output mode of period lists: points lists the timestamps and intervals lists
+// each period with its start, end and local label. Points is used when empty.
+func WithMode(mode string) Option {
+	return func(o *options) {
+		o.mode = mode
+	}
+}
+
+// WithLimit sets the maximum number of timestamps or intervals per page. The list continues on
+// the next page, after the next cursor of the response, when it holds more entries.
+func WithLimit(limit string) Option {
+	return func(o *options) {
+		o.limit = limit
+	}
+}
+
+// WithCursor continues a list after the next cursor of its previous page.
+func WithCursor(cursor string) Option {
+	return func(o *options) {
+		o.cursor = cursor
+	}
+}
+
+// WithFill sets the policy for the empty periods of an aggregation: null, zero or carry-forward.
+// Null is used when empty.
+func WithFill(fill string) Option {
+	return func(o *options) {
+		o.fill = fill
+	}
+}
+
+// WithMethod sets the resampling method: nearest, previous, linear or time-weighted. Previous is
+// used when empty.
+func WithMethod(method string) Option {
+	return func(o *options) {
+		o.method = method
+	}
+}
+
+// WithMaxGap sets the longest time, e.g. "30m", a resampled value may bridge between the points
+// it comes from. Values are unbounded when empty.
+func WithMaxGap(maxGap string) Option {
+	return func(o *options) {
+		o.maxGap = maxGap
+	}
+}
+
+// withInstants makes a listing call fn with each timestamp, or interval start, end and label, in
+// order until fn returns false, instead of emitting or collecting formatted entries. The end and
+// label are zero for timestamps.
+func withInstants(fn func(start, end time.Time, label string) bool) Option {
+	return func(o *options) {
+		o.instants = fn
+	}
+}
+
+// Options returns the options of a batch query.
+func (q BatchQuery) Options() []Option {
+	return []Option{
+		WithWeekStart(q.WeekStart),
+		WithYearStart(q.YearStart),
+		WithDST(q.DST),
+		WithInputFormat(q.InputFormat),
+		WithOutputFormat(q.OutputFormat),
+		WithLocal(q.Local),
+		WithMode(q.Mode),
+		WithLimit(limitString(q.Limit)),
+		WithCursor(q.Cursor),
+	}
+}
+
+// Options returns the options of a request with a JSON body.
+func (q PeriodOptions) Options() []Option {
+	return []Option{
+		WithWeekStart(q.WeekStart),
+		WithYearStart(q.YearStart),
+		WithDST(q.DST),
+		WithInputFormat(q.InputFormat),
+		WithOutputFormat(q.OutputFormat),
+	}
+}
+
+// Options returns the options of an aggregate request.
+func (q AggregateRequest) Options() []Option {
+	return append(q.PeriodOptions.Options(), WithFill(q.Fill))
+}
+
+// Options returns the options of a resample request.
+func (q ResampleRequest) Options() []Option {
+	return append(q.PeriodOptions.Options(), WithMethod(q.Method), WithMaxGap(q.MaxGap))
+}
+
+// limitString formats a batch query limit, 0 stands for no limit.
+func limitString(limit int) string {
+	if limit == 0 {
+		return ""
+	}
+	return strconv.Itoa(limit)
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// calendar parses the week and year start options and returns the first error, if any.
+func (o *options) calendar() (utils.Calendar, *errors.ErrResp) {
+	calendar, errResps := o.parseCalendar()
+	if len(errResps) > 0 {
+		return calendar, errResps[0]
+	}
+	return calendar, nil
+}
+
+// parseCalendar parses the week and year start options, an invalid option keeps its default.
+func (o *options) parseCalendar() (utils.Calendar, []*errors.ErrResp) {
+	calendar := utils.DefaultCalendar
+	var errResps []*errors.ErrResp
+
+	if o.weekStart != "" {
+		weekStart, errResp := utils.ParseWeekday(o.weekStart)
+		if errResp != nil {
+			errResp.Param = "week_start"
+			errResps = append(errResps, errResp)
+		} else {
+			calendar.WeekStart = weekStart
+		}
+	}
+
+	if o.yearStart != "" {
+		yearStart, errResp := utils.ParseMonth(o.yearStart)
+		if errResp != nil {
+			errResp.Param = "year_start"
+			errResps = append(errResps, errResp)
+		} else {
+			calendar.YearStart = yearStart
+		}
+	}
+
+	return calendar, errResps
+}
@@ -0,0 +1,87 @@
+package ptlist
+
+import (
+	"encoding/base64"
+	"fmt"
+	"hash/fnv"
+	"plist/errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultMaxLimit is the default maximum number of timestamps or intervals in a single response.
+const DefaultMaxLimit = 10000
+
+// WithMaxLimit sets the maximum number of timestamps or intervals in a single response. Larger
+// limits are rejected, as are lists without a limit that exceed it.
+func WithMaxLimit(limit int) ServiceOption {
+	return func(s *Service) {
+		s.maxLimit = limit
+	}
+}
+
+// page parses the limit and cursor options of the query with the given key. The limit is 0 and the
+// cursor zero when not given.
+func (o *options) page(maxLimit int, key uint64) (int, time.Time, *errors.ErrResp) {
+	var limit int
+	if o.limit != "" {
+		n, err := strconv.Atoi(o.limit)
+		if err != nil || n <= 0 || n > maxLimit {
+			errResp := errors.GetError(errors.InvalidLimit)
+			errResp.Desc = fmt.Sprintf("%s: must be between 1 and %d", errResp.Desc, maxLimit)
+			errResp.Param = "limit"
+			return 0, time.Time{}, errResp
+		}
+		limit = n
+	}
+
+	var cursor time.Time
+	if o.cursor != "" {
+		var errResp *errors.ErrResp
+		cursor, errResp = decodeCursor(o.cursor, key)
+		if errResp != nil {
+			errResp.Param = "cursor"
+			return 0, time.Time{}, errResp
+		}
+	}
+
+	return limit, cursor, nil
+}
+
+// queryKey hashes the schedule, timezone, time points and options that select the instants of a
+// query, so that a cursor only continues the query it was listed for.
+func queryKey(req PtListRequest, o *options) uint64 {
+	h := fnv.New64a()
+	for _, field := range []string{req.Period, req.RRule, req.Cron, req.TZ, req.T1, req.T2, o.weekStart, o.yearStart, o.dst, o.input, o.mode} {
+		h.Write([]byte(field))
+		h.Write([]byte{0})
+	}
+	return h.Sum64()
+}
+
+// encodeCursor encodes the last listed instant of a page along with the key of its query. The next
+// page lists the instants after it.
+func encodeCursor(last time.Time, key uint64) string {
+	value := strconv.FormatInt(last.UnixNano(), 10) + "." + strconv.FormatUint(key, 16)
+	return base64.RawURLEncoding.EncodeToString([]byte(value))
+}
+
+func decodeCursor(cursor string, key uint64) (time.Time, *errors.ErrResp) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, errors.Wrap(errors.InvalidCursor, err)
+	}
+
+	value, hash, ok := strings.Cut(string(b), ".")
+	if !ok || hash != strconv.FormatUint(key, 16) {
+		errResp := errors.GetError(errors.InvalidCursor)
+		errResp.Desc += ": cursor belongs to another query"
+		return time.Time{}, errResp
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, errors.Wrap(errors.InvalidCursor, err)
+	}
+	return time.Unix(0, n).UTC(), nil
+}
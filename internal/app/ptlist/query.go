@@ -0,0 +1,124 @@
+package ptlist
+
+import (
+	"plist/errors"
+	"plist/pkg/cron"
+	"plist/pkg/rrule"
+	"plist/utils"
+	"time"
+)
+
+// query is a parsed request: its schedule along with the calendar, DST policy, timezone and time
+// points it is listed with.
+type query struct {
+	// Exactly one of the schedules is set for a valid request.
+	period *utils.Period
+	rule   *rrule.Set
+	cron   *cron.Schedule
+
+	calendar    utils.Calendar
+	policy      utils.DSTPolicy
+	loc         *time.Location
+	inputFormat string
+	timeObj1UTC time.Time
+	timeObj2UTC time.Time
+
+	// ranged reports whether both time points were parsed.
+	ranged bool
+
+	// key identifies the query in its cursors.
+	key uint64
+}
+
+// parseQuery parses the schedule, the calendar, DST policy and input format options, tz, t1 and
+// t2 of a request. It reports every field error, in this order, and parses the time points in UTC
+// with an unknown timezone.
+func (s *Service) parseQuery(req PtListRequest, o *options) (*query, []*errors.ErrResp) {
+	q, errResps := s.parseSchedule(req, o)
+	fail := func(errResp *errors.ErrResp, param string) {
+		errResp.Param = param
+		errResps = append(errResps, errResp)
+	}
+
+	var errResp1, errResp2 *errors.ErrResp
+	q.timeObj1UTC, errResp1 = s.parseTime(req.T1, q.loc, q.calendar, q.inputFormat)
+	if errResp1 != nil {
+		fail(errResp1, "t1")
+	}
+	q.timeObj2UTC, errResp2 = s.parseTime(req.T2, q.loc, q.calendar, q.inputFormat)
+	if errResp2 != nil {
+		fail(errResp2, "t2")
+	}
+	q.ranged = errResp1 == nil && errResp2 == nil
+
+	return q, errResps
+}
+
+// parseSchedule parses a request like parseQuery, apart from its time points.
+func (s *Service) parseSchedule(req PtListRequest, o *options) (*query, []*errors.ErrResp) {
+	q := &query{
+		key: queryKey(req, o),
+	}
+	var errResps []*errors.ErrResp
+	fail := func(errResp *errors.ErrResp, param string) {
+		errResp.Param = param
+		errResps = append(errResps, errResp)
+	}
+
+	switch {
+	case req.RRule != "":
+		set, err := rrule.Parse(req.RRule)
+		if err != nil {
+			fail(errors.Wrap(errors.RRuleParsingError, err), "rrule")
+		}
+		q.rule = set
+	case req.Cron != "":
+		schedule, err := cron.Parse(req.Cron)
+		if err != nil {
+			fail(errors.Wrap(errors.CronParsingError, err), "cron")
+		}
+		q.cron = schedule
+	default:
+		p, errResp := utils.ParsePeriod(req.Period)
+		if errResp != nil {
+			fail(errResp, "period")
+		} else {
+			q.period = &p
+		}
+	}
+
+	var calendarErrResps []*errors.ErrResp
+	q.calendar, calendarErrResps = o.parseCalendar()
+	errResps = append(errResps, calendarErrResps...)
+	if q.period != nil {
+		q.period.Calendar = q.calendar
+	}
+
+	var errResp *errors.ErrResp
+	q.policy, errResp = utils.ParseDSTPolicy(o.dst)
+	if errResp != nil {
+		fail(errResp, "dst")
+	}
+
+	q.inputFormat, errResp = utils.ParseInputFormat(o.input)
+	if errResp != nil {
+		fail(errResp, "input_format")
+	}
+
+	q.loc, errResp = loadLocation(req.TZ)
+	if errResp != nil {
+		errResps = append(errResps, errResp)
+		q.loc = time.UTC
+	}
+
+	return q, errResps
+}
+
+// query parses a request and returns its first field error, if any.
+func (s *Service) query(req PtListRequest, o *options) (*query, *errors.ErrResp) {
+	q, errResps := s.parseQuery(req, o)
+	if len(errResps) > 0 {
+		return nil, errResps[0]
+	}
+	return q, nil
+}
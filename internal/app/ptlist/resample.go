@@ -0,0 +1,234 @@
+package ptlist
+
+import (
+	"context"
+	"plist/errors"
+	"plist/utils"
+	"time"
+)
+
+// Resampling methods.
+const (
+	MethodNearest      = "nearest"
+	MethodPrevious     = "previous"
+	MethodLinear       = "linear"
+	MethodTimeWeighted = "time-weighted"
+)
+
+// parseMethod parses a resampling method, previous when empty.
+func parseMethod(method string) (string, *errors.ErrResp) {
+	switch method {
+	case "":
+		return MethodPrevious, nil
+	case MethodNearest, MethodPrevious, MethodLinear, MethodTimeWeighted:
+		return method, nil
+	}
+	return "", errors.GetError(errors.UnsupportedMethod)
+}
+
+// parseMaxGap parses a maximum gap, 0 stands for none.
+func parseMaxGap(maxGap string) (time.Duration, *errors.ErrResp) {
+	if maxGap == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(maxGap)
+	if err != nil {
+		return 0, errors.Wrap(errors.InvalidMaxGap, err)
+	}
+	if d <= 0 {
+		return 0, errors.GetError(errors.InvalidMaxGap)
+	}
+	return d, nil
+}
+
+// Resample validates a request and resamples the values of a time series onto the timestamps
+// GetPtList lists between 2 time points, with the method option:
+//   - previous, the last value at or before each timestamp;
+//   - nearest, the value closest in time to each timestamp, the earlier one on ties;
+//   - linear, the value interpolated between the points around each timestamp;
+//   - time-weighted, the average over the period from each timestamp of the values held until
+//     the next point.
+//
+// With the max gap option values may bridge at most that long: from the point to the timestamp
+// for previous and nearest, between the 2 points for linear and from the point for each held value
+// of time-weighted. Timestamps without a value get null.
+func (s *Service) Resample(ctx context.Context, period, tz, t1, t2 string, points []Point, opts ...Option) (*ResampleResponse, *errors.ErrResp) {
+	if errResp := s.Validate(ctx, PtListRequest{Period: period, TZ: tz, T1: t1, T2: t2}, opts...); errResp != nil {
+		return nil, errResp
+	}
+
+	o := newOptions(opts)
+	method, errResp := parseMethod(o.method)
+	if errResp != nil {
+		errResp.Param = "method"
+		return nil, errResp
+	}
+	maxGap, errResp := parseMaxGap(o.maxGap)
+	if errResp != nil {
+		errResp.Param = "max_gap"
+		return nil, errResp
+	}
+	format, errResp := utils.ParseOutputFormat(o.output)
+	if errResp != nil {
+		errResp.Param = "output_format"
+		return nil, errResp
+	}
+	calendar, errResp := o.calendar()
+	if errResp != nil {
+		return nil, errResp
+	}
+	loc, inputFormat, errResp := parseLocation(tz, o.input)
+	if errResp != nil {
+		return nil, errResp
+	}
+
+	series, errResp := s.parseSeries(points, loc, calendar, inputFormat)
+	if errResp != nil {
+		return nil, errResp
+	}
+
+	r := &resampler{
+		series: series,
+		maxGap: maxGap,
+	}
+	resp := &ResampleResponse{
+		Samples: []Sample{},
+	}
+	errResp = s.intervals(ctx, period, tz, t1, t2, opts, func(start, end time.Time, label string) {
+		r.seek(start)
+
+		var value *float64
+		switch method {
+		case MethodNearest:
+			value = r.nearest(start)
+		case MethodPrevious:
+			value = r.previous(start)
+		case MethodLinear:
+			value = r.linear(start)
+		case MethodTimeWeighted:
+			value = r.timeWeighted(start, end)
+		}
+
+		resp.Samples = append(resp.Samples, Sample{
+			Timestamp: utils.FormatTime(start, format, loc),
+			Value:     value,
+		})
+	})
+	if errResp != nil {
+		return nil, errResp
+	}
+	return resp, nil
+}
+
+// resampler resamples a time series in time order onto increasing timestamps.
+type resampler struct {
+	series []seriesPoint
+	maxGap time.Duration
+
+	// next is the index of the first point after the current timestamp.
+	next int
+}
+
+// seek moves the resampler to a timestamp, not before the previous one.
+func (r *resampler) seek(timeObj time.Time) {
+	for r.next < len(r.series) && !r.series[r.next].timeObj.After(timeObj) {
+		r.next++
+	}
+}
+
+// bridges reports whether a value may bridge a gap.
+func (r *resampler) bridges(gap time.Duration) bool {
+	return r.maxGap == 0 || gap <= r.maxGap
+}
+
+// previous returns the last value at or before the timestamp.
+func (r *resampler) previous(timeObj time.Time) *float64 {
+	if r.next == 0 {
+		return nil
+	}
+	point := r.series[r.next-1]
+	if !r.bridges(timeObj.Sub(point.timeObj)) {
+		return nil
+	}
+	return &point.value
+}
+
+// nearest returns the value closest in time to the timestamp, the earlier one on ties.
+func (r *resampler) nearest(timeObj time.Time) *float64 {
+	var point *seriesPoint
+	var gap time.Duration
+	if r.next > 0 {
+		point = &r.series[r.next-1]
+		gap = timeObj.Sub(point.timeObj)
+	}
+	if r.next < len(r.series) && (point == nil || r.series[r.next].timeObj.Sub(timeObj) < gap) {
+		point = &r.series[r.next]
+		gap = point.timeObj.Sub(timeObj)
+	}
+	if point == nil || !r.bridges(gap) {
+		return nil
+	}
+	value := point.value
+	return &value
+}
+
+// linear returns the value interpolated between the points around the timestamp.
+func (r *resampler) linear(timeObj time.Time) *float64 {
+	if r.next == 0 {
+		return nil
+	}
+	before := r.series[r.next-1]
+	if before.timeObj.Equal(timeObj) {
+		return &before.value
+	}
+	if r.next == len(r.series) {
+		return nil
+	}
+	after := r.series[r.next]
+	gap := after.timeObj.Sub(before.timeObj)
+	if !r.bridges(gap) {
+		return nil
+	}
+
+	value := before.value + (after.value-before.value)*float64(timeObj.Sub(before.timeObj))/float64(gap)
+	return &value
+}
+
+// timeWeighted returns the average of the values held from the start of a period up to its end,
+// each until the next point, weighted by how long they are held.
+func (r *resampler) timeWeighted(start, end time.Time) *float64 {
+	var sum, held float64
+	hold := r.next - 1
+	next := r.next
+	for from := start; from.Before(end); {
+		to := end
+		if next < len(r.series) && r.series[next].timeObj.Before(end) {
+			to = r.series[next].timeObj
+		}
+
+		if hold >= 0 {
+			until := to
+			if r.maxGap > 0 && r.series[hold].timeObj.Add(r.maxGap).Before(until) {
+				until = r.series[hold].timeObj.Add(r.maxGap)
+			}
+			if until.After(from) {
+				d := until.Sub(from).Seconds()
+				sum += r.series[hold].value * d
+				held += d
+			}
+		}
+
+		// The last of the points at the same time holds.
+		for next < len(r.series) && r.series[next].timeObj.Equal(to) {
+			hold = next
+			next++
+		}
+		from = to
+	}
+
+	if held == 0 {
+		return nil
+	}
+	value := sum / held
+	return &value
+}
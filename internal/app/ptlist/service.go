@@ -8,74 +8,321 @@ import (
 )
 
 // Service struct represents ptlist service.
-type Service struct{}
+type Service struct {
+	clock        Clock
+	rangeLimit   int
+	batchWorkers int
+	batchLimit   int
+	batchEntries int
+	maxLimit     int
+	maxPoints    int
+}
 
-// NewService service constructor.
-func NewService() *Service {
-	return &Service{}
+// NewService service constructor. The service reads the system clock unless WithClock is given.
+func NewService(opts ...ServiceOption) *Service {
+	s := &Service{
+		clock:        realClock{},
+		rangeLimit:   DefaultRangeLimit,
+		batchWorkers: DefaultBatchWorkers,
+		batchLimit:   DefaultBatchLimit,
+		batchEntries: DefaultBatchEntries,
+		maxLimit:     DefaultMaxLimit,
+		maxPoints:    DefaultMaxPoints,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // GetPtList returns a list of all matching timestamps of a periodic task between 2 time points
-// in UTC in the following form: 20060102T150405Z.
-func (s *Service) GetPtList(ctx context.Context, period, tz, t1, t2 string) (*PtListResponse, *errors.ErrResp) {
-	loc, err := time.LoadLocation(tz)
-	if utils.CheckErr(err) {
-		return nil, errors.GetError(errors.TimezoneLoadingError)
+// in UTC in the following form: 20060102T150405Z, unless another output format option is given.
+//
+// The period is a positive multiple of a unit: m (minute), h (hour), d (day), w (week),
+// mo (month), q (quarter) or y (year), e.g. 15m, 2h, 3d, 1w, 2mo, 1q or 5y. Period boundaries
+// are computed on the local wall clock of the given timezone and wall-clock times inside daylight
+// saving gaps and overlaps are resolved with the DST policy option. In the intervals mode each
+// timestamp starts an interval that ends at the next one, or at the end of its period if
+// earlier, so days across daylight saving transitions last 23 or 25 hours.
+func (s *Service) GetPtList(ctx context.Context, period, tz, t1, t2 string, opts ...Option) (*PtListResponse, *errors.ErrResp) {
+	l, errResp := s.ptList(ctx, period, tz, t1, t2, nil, opts)
+	if errResp != nil {
+		return nil, errResp
+	}
+	return l.resp, nil
+}
+
+// ptList emits the entries of GetPtList through emit, or collects them when emit is nil.
+func (s *Service) ptList(ctx context.Context, period, tz, t1, t2 string, emit func(Entry) bool, opts []Option) (*listing, *errors.ErrResp) {
+	o := newOptions(opts)
+
+	q, errResp := s.query(PtListRequest{Period: period, TZ: tz, T1: t1, T2: t2}, o)
+	if errResp != nil {
+		return nil, errResp
+	}
+
+	return s.periodList(ctx, o, q.key, *q.period, q.policy, q.loc, q.timeObj1UTC, q.timeObj2UTC, emit)
+}
+
+// periodList emits the timestamps of a period between 2 parsed time points of the query with the
+// given key through emit, or collects them when emit is nil.
+func (s *Service) periodList(ctx context.Context, o *options, key uint64, p utils.Period, policy utils.DSTPolicy, loc *time.Location, timeObj1UTC, timeObj2UTC time.Time, emit func(Entry) bool) (*listing, *errors.ErrResp) {
+	l, errResp := o.listing(ctx, key, loc, timeObj1UTC, timeObj2UTC, &p, s.maxLimit, emit)
+	if errResp != nil {
+		return nil, errResp
 	}
 
-	// Get the current time in UTC and the specified location.
-	timeNowInLocation := time.Now().In(loc)
+	// Round on the local wall clock.
+	wallClock1 := utils.FirstWallClock(l.start(), loc)
+	wallClock2 := utils.LastWallClock(timeObj2UTC, loc)
+	errResp = utils.Round(&wallClock1, &wallClock2, p)
+	if errResp != nil {
+		return nil, errResp
+	}
 
-	// Get the offset in seconds for each time zone
-	// for the time zones
-	_, currentTimeLocationOffset := timeNowInLocation.Zone()
+	// Intervals mode continues past the range until the last interval ends.
+	timeline := utils.NewTimeline(loc, policy)
+	for (!wallClock1.After(wallClock2) || timeline.Holds(timeObj2UTC) || l.open()) && !l.done {
+		timeline.Add(wallClock1, l.add)
 
-	// UTC t1
-	timeObj1UTC, err := time.Parse("20060102T150405Z", t1)
-	if utils.CheckErr(err) {
-		return nil, errors.GetError(errors.TimeParsingError)
+		errResp := utils.AddPeriod(&wallClock1, p)
+		if errResp != nil {
+			return nil, errResp
+		}
 	}
+	timeline.Flush(l.add)
 
-	// UTC t2
-	timeObj2UTC, err := time.Parse("20060102T150405Z", t2)
-	if utils.CheckErr(err) {
-		return nil, errors.GetError(errors.TimeParsingError)
+	if l.err != nil {
+		return nil, l.err
 	}
+	return l, nil
+}
 
-	errResp := utils.Round(&timeObj1UTC, &timeObj2UTC, period)
-	if utils.CheckErr(errResp) {
+// GetRRuleList returns the occurrences of an RFC 5545 recurrence rule between 2 time points
+// in UTC in the following form: 20060102T150405Z, unless another output format option is given.
+//
+// The rule is either a bare RRULE value, e.g. FREQ=MONTHLY;BYDAY=-1FR;BYHOUR=9, or iCalendar
+// content lines with DTSTART, RRULE and EXDATE properties. Floating times are read on the wall
+// clock of the given timezone and DTSTART defaults to t1. Occurrences inside daylight saving gaps
+// and overlaps are resolved with the DST policy option.
+func (s *Service) GetRRuleList(ctx context.Context, rule, tz, t1, t2 string, opts ...Option) (*PtListResponse, *errors.ErrResp) {
+	l, errResp := s.rruleList(ctx, rule, tz, t1, t2, nil, opts)
+	if errResp != nil {
 		return nil, errResp
 	}
+	return l.resp, nil
+}
+
+// rruleList emits the entries of GetRRuleList through emit, or collects them when emit is nil.
+func (s *Service) rruleList(ctx context.Context, rule, tz, t1, t2 string, emit func(Entry) bool, opts []Option) (*listing, *errors.ErrResp) {
+	o := newOptions(opts)
 
-	// Local t1
-	timeObj1Local := timeObj1UTC.In(loc)
-	_, timeObj1UTC = utils.NormalizeTime(timeObj1Local, timeObj1UTC)
+	q, errResp := s.query(PtListRequest{RRule: rule, TZ: tz, T1: t1, T2: t2}, o)
+	if errResp != nil {
+		return nil, errResp
+	}
+	set, policy, loc, timeObj1UTC, timeObj2UTC := q.rule, q.policy, q.loc, q.timeObj1UTC, q.timeObj2UTC
 
-	// Local t2
-	timeObj2Local := timeObj2UTC.In(loc)
-	_, timeObj2UTC = utils.NormalizeTime(timeObj2Local, timeObj2UTC)
+	var err error
+	dtstart := utils.WallClock(timeObj1UTC.In(loc))
+	if set.DTStart != nil {
+		dtstart, err = set.DTStart.WallClock(loc)
+		if err != nil {
+			return nil, rruleTimezoneError(err)
+		}
+	}
 
-	timestamps := []string{}
-	for !timeObj1UTC.After(timeObj2UTC) {
-		// Get the offset in seconds for each time zone for the time zones.
-		_, givenTimeLocationOffset := timeObj1UTC.In(loc).Zone()
-		duration := (currentTimeLocationOffset - givenTimeLocationOffset) / 60 / 60
-		if duration != 0 {
-			currentTimeLocationOffset = givenTimeLocationOffset
-			timeObj1UTC = timeObj1UTC.Add(time.Duration(duration) * time.Hour)
+	// Wall-clock times may be up to a day away from their instant, the exact range is checked below.
+	end := utils.WallClock(timeObj2UTC.In(loc)).AddDate(0, 0, 1)
+	if set.Rule.Until != nil {
+		until, err := set.Rule.Until.WallClock(loc)
+		if err != nil {
+			return nil, rruleTimezoneError(err)
+		}
+		if until.Before(end) {
+			end = until
+		}
+	}
 
-			timeObj2UTC = timeObj2UTC.Add(time.Duration(duration) * time.Hour)
+	exdates := map[time.Time]bool{}
+	for _, exdate := range set.ExDates {
+		wallClock, err := exdate.WallClock(loc)
+		if err != nil {
+			return nil, rruleTimezoneError(err)
+		}
+		for _, timeObj := range utils.Resolve(wallClock, loc, utils.DSTBoth) {
+			exdates[timeObj.UTC()] = true
 		}
+	}
 
-		timestamps = append(timestamps, timeObj1UTC.Format("20060102T150405Z"))
+	l, errResp := o.listing(ctx, q.key, loc, timeObj1UTC, timeObj2UTC, nil, s.maxLimit, emit)
+	if errResp != nil {
+		return nil, errResp
+	}
 
-		errResp := utils.AddPeriod(&timeObj1UTC, period)
-		if utils.CheckErr(err) {
-			return nil, errResp
+	add := func(timeObj time.Time) {
+		if !exdates[timeObj] {
+			l.add(timeObj)
 		}
 	}
+	start := utils.WallClock(l.start().In(loc)).AddDate(0, 0, -1)
+	timeline := utils.NewTimeline(loc, policy)
+	err = set.Rule.Iterate(dtstart, start, end, func(wallClock time.Time) bool {
+		timeline.Add(wallClock, add)
+		return !l.done
+	})
+	if err != nil {
+		errResp := errors.Wrap(errors.RangeTooLarge, err)
+		errResp.Param = "rrule"
+		return nil, errResp
+	}
+	timeline.Flush(add)
+
+	if l.err != nil {
+		return nil, l.err
+	}
+	return l, nil
+}
+
+// GetCronList returns the times matched by a cron expression between 2 time points in UTC in
+// the following form: 20060102T150405Z, unless another output format option is given.
+//
+// Both the standard 5-field expression and the 6-field form with leading seconds are accepted.
+// The expression is matched on the wall clock of the given timezone and matches inside daylight
+// saving gaps and overlaps are resolved with the DST policy option.
+func (s *Service) GetCronList(ctx context.Context, expr, tz, t1, t2 string, opts ...Option) (*PtListResponse, *errors.ErrResp) {
+	l, errResp := s.cronList(ctx, expr, tz, t1, t2, nil, opts)
+	if errResp != nil {
+		return nil, errResp
+	}
+	return l.resp, nil
+}
+
+// cronList emits the entries of GetCronList through emit, or collects them when emit is nil.
+func (s *Service) cronList(ctx context.Context, expr, tz, t1, t2 string, emit func(Entry) bool, opts []Option) (*listing, *errors.ErrResp) {
+	o := newOptions(opts)
+
+	q, errResp := s.query(PtListRequest{Cron: expr, TZ: tz, T1: t1, T2: t2}, o)
+	if errResp != nil {
+		return nil, errResp
+	}
+	schedule, policy, loc, timeObj1UTC, timeObj2UTC := q.cron, q.policy, q.loc, q.timeObj1UTC, q.timeObj2UTC
+
+	l, errResp := o.listing(ctx, q.key, loc, timeObj1UTC, timeObj2UTC, nil, s.maxLimit, emit)
+	if errResp != nil {
+		return nil, errResp
+	}
+
+	// Wall-clock times may be up to a day away from their instant, the exact range is checked below.
+	start := utils.WallClock(l.start().In(loc)).AddDate(0, 0, -1)
+	end := utils.WallClock(timeObj2UTC.In(loc)).AddDate(0, 0, 1)
+
+	timeline := utils.NewTimeline(loc, policy)
+	schedule.Iterate(start, end, func(wallClock time.Time) bool {
+		timeline.Add(wallClock, l.add)
+		return !l.done
+	})
+	timeline.Flush(l.add)
+
+	if l.err != nil {
+		return nil, l.err
+	}
+	return l, nil
+}
+
+// List validates a request and returns the list of its schedule: the recurrence rule if given,
+// else the cron expression if given, else the period.
+func (s *Service) List(ctx context.Context, req PtListRequest, opts ...Option) (*PtListResponse, *errors.ErrResp) {
+	l, errResp := s.list(ctx, req, nil, opts)
+	if errResp != nil {
+		return nil, errResp
+	}
+	return l.resp, nil
+}
+
+// Stream validates a request and emits the entries of its schedule through fn in order, until fn
+// returns false or the context is done. Unlike List it holds no entries in memory, so it is not
+// bound by the maximum limit of the service. Period lists are still bound by its range limit,
+// recurrence rules and cron expressions only by t1 and t2.
+func (s *Service) Stream(ctx context.Context, req PtListRequest, fn func(Entry) bool, opts ...Option) *errors.ErrResp {
+	emit := func(entry Entry) bool {
+		return ctx.Err() == nil && fn(entry)
+	}
+	if _, errResp := s.list(ctx, req, emit, opts); errResp != nil {
+		return errResp
+	}
+
+	if err := ctx.Err(); err != nil {
+		return errors.Wrap(errors.RequestCanceled, err)
+	}
+	return nil
+}
+
+// list validates a request and lists its schedule.
+func (s *Service) list(ctx context.Context, req PtListRequest, emit func(Entry) bool, opts []Option) (*listing, *errors.ErrResp) {
+	if errResp := s.Validate(ctx, req, opts...); errResp != nil {
+		return nil, errResp
+	}
+	return s.schedule(ctx, req, emit, opts)
+}
+
+// schedule lists the schedule of a validated request.
+func (s *Service) schedule(ctx context.Context, req PtListRequest, emit func(Entry) bool, opts []Option) (*listing, *errors.ErrResp) {
+	switch {
+	case req.RRule != "":
+		return s.rruleList(ctx, req.RRule, req.TZ, req.T1, req.T2, emit, opts)
+	case req.Cron != "":
+		return s.cronList(ctx, req.Cron, req.TZ, req.T1, req.T2, emit, opts)
+	}
+	return s.ptList(ctx, req.Period, req.TZ, req.T1, req.T2, emit, opts)
+}
+
+// rruleTimezoneError reports an unknown TZID of a recurrence rule.
+func rruleTimezoneError(err error) *errors.ErrResp {
+	errResp := errors.Wrap(errors.TimezoneLoadingError, err)
+	errResp.Param = "rrule"
+	return errResp
+}
+
+// parseLocation loads the timezone location and parses the input format of its time points.
+func parseLocation(tz, inputFormat string) (*time.Location, string, *errors.ErrResp) {
+	inputFormat, errResp := utils.ParseInputFormat(inputFormat)
+	if errResp != nil {
+		errResp.Param = "input_format"
+		return nil, "", errResp
+	}
+
+	loc, errResp := loadLocation(tz)
+	if errResp != nil {
+		return nil, "", errResp
+	}
+
+	return loc, inputFormat, nil
+}
 
-	return &PtListResponse{
-		Timestamps: timestamps,
-	}, nil
+// loadLocation loads a timezone location.
+func loadLocation(tz string) (*time.Location, *errors.ErrResp) {
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		errResp := errors.Wrap(errors.TimezoneLoadingError, err)
+		errResp.Param = "tz"
+		return nil, errResp
+	}
+	return loc, nil
+}
+
+// parseTime parses a time point in the given input format. When no format is given it detects an
+// absolute format, e.g. 20060102T150405Z, 2006-01-02T15:04:05+02:00 or epoch seconds, and falls
+// back to a relative expression, e.g. now-7d or startofmonth-1mo, evaluated on the service clock.
+func (s *Service) parseTime(value string, loc *time.Location, calendar utils.Calendar, inputFormat string) (time.Time, *errors.ErrResp) {
+	timeObj, errResp := utils.ParseTime(value, inputFormat, loc)
+	if errResp == nil || inputFormat != "" {
+		return timeObj, errResp
+	}
+
+	timeObj, errResp = utils.ParseRelativeTime(value, s.clock.Now(), loc, calendar)
+	if errResp != nil {
+		return time.Time{}, errResp
+	}
+	return timeObj.UTC(), nil
 }
@@ -4,6 +4,11 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
+
+	pterrors "plist/errors"
+	"plist/pkg/cron"
+	"plist/utils"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -19,7 +24,7 @@ func TestPtListHappyPathAthensTZ(t *testing.T) {
 			name:  "Hour test",
 			input: []string{"1h", "Europe/Athens", "20210714T204603Z", "20210715T123456Z"},
 			expectedOutput: &PtListResponse{
-				[]string{
+				Timestamps: []string{
 					"20210714T210000Z",
 					"20210714T220000Z",
 					"20210714T230000Z",
@@ -42,7 +47,7 @@ func TestPtListHappyPathAthensTZ(t *testing.T) {
 			name:  "Day test",
 			input: []string{"1d", "Europe/Athens", "20211010T204603Z", "20211115T123456Z"},
 			expectedOutput: &PtListResponse{
-				[]string{
+				Timestamps: []string{
 					"20211010T210000Z",
 					"20211011T210000Z",
 					"20211012T210000Z",
@@ -85,7 +90,7 @@ func TestPtListHappyPathAthensTZ(t *testing.T) {
 			name:  "Month test",
 			input: []string{"1mo", "Europe/Athens", "20210214T204603Z", "20211115T123456Z"},
 			expectedOutput: &PtListResponse{
-				[]string{
+				Timestamps: []string{
 					"20210228T220000Z",
 					"20210331T210000Z",
 					"20210430T210000Z",
@@ -101,7 +106,7 @@ func TestPtListHappyPathAthensTZ(t *testing.T) {
 			name:  "Year test",
 			input: []string{"1y", "Europe/Athens", "20180214T204603Z", "20211115T123456Z"},
 			expectedOutput: &PtListResponse{
-				[]string{
+				Timestamps: []string{
 					"20181231T220000Z",
 					"20191231T220000Z",
 					"20201231T220000Z"},
@@ -133,7 +138,7 @@ func TestPtListHappyPathSwedenTZ(t *testing.T) {
 			name:  "Hour test",
 			input: []string{"1h", "Europe/Stockholm", "20210714T204603Z", "20210715T123456Z"},
 			expectedOutput: &PtListResponse{
-				[]string{
+				Timestamps: []string{
 					"20210714T210000Z",
 					"20210714T220000Z",
 					"20210714T230000Z",
@@ -156,69 +161,69 @@ func TestPtListHappyPathSwedenTZ(t *testing.T) {
 			name:  "Day test",
 			input: []string{"1d", "Europe/Stockholm", "20211010T204603Z", "20211115T123456Z"},
 			expectedOutput: &PtListResponse{
-				[]string{
-					"20211010T210000Z",
-					"20211011T210000Z",
-					"20211012T210000Z",
-					"20211013T210000Z",
-					"20211014T210000Z",
-					"20211015T210000Z",
-					"20211016T210000Z",
-					"20211017T210000Z",
-					"20211018T210000Z",
-					"20211019T210000Z",
-					"20211020T210000Z",
-					"20211021T210000Z",
-					"20211022T210000Z",
-					"20211023T210000Z",
-					"20211024T210000Z",
-					"20211025T210000Z",
-					"20211026T210000Z",
-					"20211027T210000Z",
-					"20211028T210000Z",
-					"20211029T210000Z",
-					"20211030T210000Z",
-					"20211031T220000Z",
-					"20211101T220000Z",
-					"20211102T220000Z",
-					"20211103T220000Z",
-					"20211104T220000Z",
-					"20211105T220000Z",
-					"20211106T220000Z",
-					"20211107T220000Z",
-					"20211108T220000Z",
-					"20211109T220000Z",
-					"20211110T220000Z",
-					"20211111T220000Z",
-					"20211112T220000Z",
-					"20211113T220000Z",
-					"20211114T220000Z"},
+				Timestamps: []string{
+					"20211010T220000Z",
+					"20211011T220000Z",
+					"20211012T220000Z",
+					"20211013T220000Z",
+					"20211014T220000Z",
+					"20211015T220000Z",
+					"20211016T220000Z",
+					"20211017T220000Z",
+					"20211018T220000Z",
+					"20211019T220000Z",
+					"20211020T220000Z",
+					"20211021T220000Z",
+					"20211022T220000Z",
+					"20211023T220000Z",
+					"20211024T220000Z",
+					"20211025T220000Z",
+					"20211026T220000Z",
+					"20211027T220000Z",
+					"20211028T220000Z",
+					"20211029T220000Z",
+					"20211030T220000Z",
+					"20211031T230000Z",
+					"20211101T230000Z",
+					"20211102T230000Z",
+					"20211103T230000Z",
+					"20211104T230000Z",
+					"20211105T230000Z",
+					"20211106T230000Z",
+					"20211107T230000Z",
+					"20211108T230000Z",
+					"20211109T230000Z",
+					"20211110T230000Z",
+					"20211111T230000Z",
+					"20211112T230000Z",
+					"20211113T230000Z",
+					"20211114T230000Z"},
 			},
 		},
 		{
 			name:  "Month test",
 			input: []string{"1mo", "Europe/Stockholm", "20210214T204603Z", "20211115T123456Z"},
 			expectedOutput: &PtListResponse{
-				[]string{
-					"20210228T220000Z",
-					"20210331T210000Z",
-					"20210430T210000Z",
-					"20210531T210000Z",
-					"20210630T210000Z",
-					"20210731T210000Z",
-					"20210831T210000Z",
-					"20210930T210000Z",
-					"20211031T220000Z"},
+				Timestamps: []string{
+					"20210228T230000Z",
+					"20210331T220000Z",
+					"20210430T220000Z",
+					"20210531T220000Z",
+					"20210630T220000Z",
+					"20210731T220000Z",
+					"20210831T220000Z",
+					"20210930T220000Z",
+					"20211031T230000Z"},
 			},
 		},
 		{
 			name:  "Year test",
 			input: []string{"1y", "Europe/Stockholm", "20180214T204603Z", "20211115T123456Z"},
 			expectedOutput: &PtListResponse{
-				[]string{
-					"20181231T220000Z",
-					"20191231T220000Z",
-					"20201231T220000Z"},
+				Timestamps: []string{
+					"20181231T230000Z",
+					"20191231T230000Z",
+					"20201231T230000Z"},
 			},
 		},
 	}
@@ -247,7 +252,7 @@ func TestPtListHappyPathAbidjanTZ(t *testing.T) {
 			name:  "Hour test",
 			input: []string{"1h", "Africa/Abidjan", "20210714T204603Z", "20210715T123456Z"},
 			expectedOutput: &PtListResponse{
-				[]string{
+				Timestamps: []string{
 					"20210714T210000Z",
 					"20210714T220000Z",
 					"20210714T230000Z",
@@ -270,69 +275,69 @@ func TestPtListHappyPathAbidjanTZ(t *testing.T) {
 			name:  "Day test",
 			input: []string{"1d", "Africa/Abidjan", "20211010T204603Z", "20211115T123456Z"},
 			expectedOutput: &PtListResponse{
-				[]string{
-					"20211010T210000Z",
-					"20211011T210000Z",
-					"20211012T210000Z",
-					"20211013T210000Z",
-					"20211014T210000Z",
-					"20211015T210000Z",
-					"20211016T210000Z",
-					"20211017T210000Z",
-					"20211018T210000Z",
-					"20211019T210000Z",
-					"20211020T210000Z",
-					"20211021T210000Z",
-					"20211022T210000Z",
-					"20211023T210000Z",
-					"20211024T210000Z",
-					"20211025T210000Z",
-					"20211026T210000Z",
-					"20211027T210000Z",
-					"20211028T210000Z",
-					"20211029T210000Z",
-					"20211030T210000Z",
-					"20211031T210000Z",
-					"20211101T210000Z",
-					"20211102T210000Z",
-					"20211103T210000Z",
-					"20211104T210000Z",
-					"20211105T210000Z",
-					"20211106T210000Z",
-					"20211107T210000Z",
-					"20211108T210000Z",
-					"20211109T210000Z",
-					"20211110T210000Z",
-					"20211111T210000Z",
-					"20211112T210000Z",
-					"20211113T210000Z",
-					"20211114T210000Z"},
+				Timestamps: []string{
+					"20211011T000000Z",
+					"20211012T000000Z",
+					"20211013T000000Z",
+					"20211014T000000Z",
+					"20211015T000000Z",
+					"20211016T000000Z",
+					"20211017T000000Z",
+					"20211018T000000Z",
+					"20211019T000000Z",
+					"20211020T000000Z",
+					"20211021T000000Z",
+					"20211022T000000Z",
+					"20211023T000000Z",
+					"20211024T000000Z",
+					"20211025T000000Z",
+					"20211026T000000Z",
+					"20211027T000000Z",
+					"20211028T000000Z",
+					"20211029T000000Z",
+					"20211030T000000Z",
+					"20211031T000000Z",
+					"20211101T000000Z",
+					"20211102T000000Z",
+					"20211103T000000Z",
+					"20211104T000000Z",
+					"20211105T000000Z",
+					"20211106T000000Z",
+					"20211107T000000Z",
+					"20211108T000000Z",
+					"20211109T000000Z",
+					"20211110T000000Z",
+					"20211111T000000Z",
+					"20211112T000000Z",
+					"20211113T000000Z",
+					"20211114T000000Z",
+					"20211115T000000Z"},
 			},
 		},
 		{
 			name:  "Month test",
 			input: []string{"1mo", "Africa/Abidjan", "20210214T204603Z", "20211115T123456Z"},
 			expectedOutput: &PtListResponse{
-				[]string{
-					"20210228T210000Z",
-					"20210331T210000Z",
-					"20210430T210000Z",
-					"20210531T210000Z",
-					"20210630T210000Z",
-					"20210731T210000Z",
-					"20210831T210000Z",
-					"20210930T210000Z",
-					"20211031T210000Z"},
+				Timestamps: []string{
+					"20210301T000000Z",
+					"20210401T000000Z",
+					"20210501T000000Z",
+					"20210601T000000Z",
+					"20210701T000000Z",
+					"20210801T000000Z",
+					"20210901T000000Z",
+					"20211001T000000Z",
+					"20211101T000000Z"},
 			},
 		},
 		{
 			name:  "Year test",
 			input: []string{"1y", "Africa/Abidjan", "20180214T204603Z", "20211115T123456Z"},
 			expectedOutput: &PtListResponse{
-				[]string{
-					"20181231T210000Z",
-					"20191231T210000Z",
-					"20201231T210000Z"},
+				Timestamps: []string{
+					"20190101T000000Z",
+					"20200101T000000Z",
+					"20210101T000000Z"},
 			},
 		},
 	}
@@ -361,7 +366,7 @@ func TestPtListHappyPathNewYorkTZ(t *testing.T) {
 			name:  "Hour test",
 			input: []string{"1h", "America/New_York", "20210714T204603Z", "20210715T123456Z"},
 			expectedOutput: &PtListResponse{
-				[]string{
+				Timestamps: []string{
 					"20210714T210000Z",
 					"20210714T220000Z",
 					"20210714T230000Z",
@@ -384,69 +389,69 @@ func TestPtListHappyPathNewYorkTZ(t *testing.T) {
 			name:  "Day test",
 			input: []string{"1d", "America/New_York", "20211010T204603Z", "20211115T123456Z"},
 			expectedOutput: &PtListResponse{
-				[]string{
-					"20211010T210000Z",
-					"20211011T210000Z",
-					"20211012T210000Z",
-					"20211013T210000Z",
-					"20211014T210000Z",
-					"20211015T210000Z",
-					"20211016T210000Z",
-					"20211017T210000Z",
-					"20211018T210000Z",
-					"20211019T210000Z",
-					"20211020T210000Z",
-					"20211021T210000Z",
-					"20211022T210000Z",
-					"20211023T210000Z",
-					"20211024T210000Z",
-					"20211025T210000Z",
-					"20211026T210000Z",
-					"20211027T210000Z",
-					"20211028T210000Z",
-					"20211029T210000Z",
-					"20211030T210000Z",
-					"20211031T210000Z",
-					"20211101T210000Z",
-					"20211102T210000Z",
-					"20211103T210000Z",
-					"20211104T210000Z",
-					"20211105T210000Z",
-					"20211106T210000Z",
-					"20211107T220000Z",
-					"20211108T220000Z",
-					"20211109T220000Z",
-					"20211110T220000Z",
-					"20211111T220000Z",
-					"20211112T220000Z",
-					"20211113T220000Z",
-					"20211114T220000Z"},
+				Timestamps: []string{
+					"20211011T040000Z",
+					"20211012T040000Z",
+					"20211013T040000Z",
+					"20211014T040000Z",
+					"20211015T040000Z",
+					"20211016T040000Z",
+					"20211017T040000Z",
+					"20211018T040000Z",
+					"20211019T040000Z",
+					"20211020T040000Z",
+					"20211021T040000Z",
+					"20211022T040000Z",
+					"20211023T040000Z",
+					"20211024T040000Z",
+					"20211025T040000Z",
+					"20211026T040000Z",
+					"20211027T040000Z",
+					"20211028T040000Z",
+					"20211029T040000Z",
+					"20211030T040000Z",
+					"20211031T040000Z",
+					"20211101T040000Z",
+					"20211102T040000Z",
+					"20211103T040000Z",
+					"20211104T040000Z",
+					"20211105T040000Z",
+					"20211106T040000Z",
+					"20211107T040000Z",
+					"20211108T050000Z",
+					"20211109T050000Z",
+					"20211110T050000Z",
+					"20211111T050000Z",
+					"20211112T050000Z",
+					"20211113T050000Z",
+					"20211114T050000Z",
+					"20211115T050000Z"},
 			},
 		},
 		{
 			name:  "Month test",
 			input: []string{"1mo", "America/New_York", "20210214T204603Z", "20211115T123456Z"},
 			expectedOutput: &PtListResponse{
-				[]string{
-					"20210228T220000Z",
-					"20210331T210000Z",
-					"20210430T210000Z",
-					"20210531T210000Z",
-					"20210630T210000Z",
-					"20210731T210000Z",
-					"20210831T210000Z",
-					"20210930T210000Z",
-					"20211031T210000Z"},
+				Timestamps: []string{
+					"20210301T050000Z",
+					"20210401T040000Z",
+					"20210501T040000Z",
+					"20210601T040000Z",
+					"20210701T040000Z",
+					"20210801T040000Z",
+					"20210901T040000Z",
+					"20211001T040000Z",
+					"20211101T040000Z"},
 			},
 		},
 		{
 			name:  "Year test",
 			input: []string{"1y", "America/New_York", "20180214T204603Z", "20211115T123456Z"},
 			expectedOutput: &PtListResponse{
-				[]string{
-					"20181231T220000Z",
-					"20191231T220000Z",
-					"20201231T220000Z"},
+				Timestamps: []string{
+					"20190101T050000Z",
+					"20200101T050000Z",
+					"20210101T050000Z"},
 			},
 		},
 	}
@@ -475,13 +480,7 @@ func TestPtListHappyPathTokyoTZ(t *testing.T) {
 			name:  "Hour test",
 			input: []string{"1h", "Asia/Tokyo", "20210214T204603Z", "20210215T123456Z"},
 			expectedOutput: &PtListResponse{
-				[]string{
-					"20210214T150000Z",
-					"20210214T160000Z",
-					"20210214T170000Z",
-					"20210214T180000Z",
-					"20210214T190000Z",
-					"20210214T200000Z",
+				Timestamps: []string{
 					"20210214T210000Z",
 					"20210214T220000Z",
 					"20210214T230000Z",
@@ -504,8 +503,7 @@ func TestPtListHappyPathTokyoTZ(t *testing.T) {
 			name:  "Day test",
 			input: []string{"1d", "Asia/Tokyo", "20210214T204603Z", "20210315T123456Z"},
 			expectedOutput: &PtListResponse{
-				[]string{
-					"20210214T150000Z",
+				Timestamps: []string{
 					"20210215T150000Z",
 					"20210216T150000Z",
 					"20210217T150000Z",
@@ -540,7 +538,7 @@ func TestPtListHappyPathTokyoTZ(t *testing.T) {
 			name:  "Month test",
 			input: []string{"1mo", "Asia/Tokyo", "20210214T204603Z", "20211115T123456Z"},
 			expectedOutput: &PtListResponse{
-				[]string{
+				Timestamps: []string{
 					"20210228T150000Z",
 					"20210331T150000Z",
 					"20210430T150000Z",
@@ -556,7 +554,7 @@ func TestPtListHappyPathTokyoTZ(t *testing.T) {
 			name:  "Year test",
 			input: []string{"1y", "Asia/Tokyo", "20210214T204603Z", "20271115T123456Z"},
 			expectedOutput: &PtListResponse{
-				[]string{
+				Timestamps: []string{
 					"20211231T150000Z",
 					"20221231T150000Z",
 					"20231231T150000Z",
@@ -591,7 +589,10 @@ func TestPtListHappyPathMexicoCityTZ(t *testing.T) {
 			name:  "Hour test",
 			input: []string{"1h", "America/Mexico_City", "20210214T024603Z", "20210215T053456Z"},
 			expectedOutput: &PtListResponse{
-				[]string{
+				Timestamps: []string{
+					"20210214T030000Z",
+					"20210214T040000Z",
+					"20210214T050000Z",
 					"20210214T060000Z",
 					"20210214T070000Z",
 					"20210214T080000Z",
@@ -615,15 +616,14 @@ func TestPtListHappyPathMexicoCityTZ(t *testing.T) {
 					"20210215T020000Z",
 					"20210215T030000Z",
 					"20210215T040000Z",
-					"20210215T050000Z",
-					"20210215T060000Z"},
+					"20210215T050000Z"},
 			},
 		},
 		{
 			name:  "Day test",
 			input: []string{"1d", "America/Mexico_City", "20210214T024603Z", "20210315T053456Z"},
 			expectedOutput: &PtListResponse{
-				[]string{
+				Timestamps: []string{
 					"20210214T060000Z",
 					"20210215T060000Z",
 					"20210216T060000Z",
@@ -659,28 +659,29 @@ func TestPtListHappyPathMexicoCityTZ(t *testing.T) {
 			name:  "Month test",
 			input: []string{"1mo", "America/Mexico_City", "20210214T024603Z", "20211115T053456Z"},
 			expectedOutput: &PtListResponse{
-				[]string{
-					"20210228T060000Z",
-					"20210331T060000Z",
-					"20210430T050000Z",
-					"20210531T050000Z",
-					"20210630T050000Z",
-					"20210731T050000Z",
-					"20210831T050000Z",
-					"20210930T050000Z"},
+				Timestamps: []string{
+					"20210301T060000Z",
+					"20210401T060000Z",
+					"20210501T050000Z",
+					"20210601T050000Z",
+					"20210701T050000Z",
+					"20210801T050000Z",
+					"20210901T050000Z",
+					"20211001T050000Z",
+					"20211101T060000Z"},
 			},
 		},
 		{
 			name:  "Year test",
 			input: []string{"1y", "America/Mexico_City", "20210214T024603Z", "20271115T053456Z"},
 			expectedOutput: &PtListResponse{
-				[]string{
-					"20211231T060000Z",
-					"20221231T060000Z",
-					"20231231T060000Z",
-					"20241231T060000Z",
-					"20251231T060000Z",
-					"20261231T060000Z"},
+				Timestamps: []string{
+					"20220101T060000Z",
+					"20230101T060000Z",
+					"20240101T060000Z",
+					"20250101T060000Z",
+					"20260101T060000Z",
+					"20270101T060000Z"},
 			},
 		},
 	}
@@ -699,18 +700,74 @@ func TestPtListHappyPathMexicoCityTZ(t *testing.T) {
 	}
 }
 
-func TestPtListUnhappyPath(t *testing.T) {
+func TestPtListHappyPathMultiples(t *testing.T) {
 	testcases := []struct {
 		name           string
 		input          []string
 		expectedOutput *PtListResponse
-		expectedError  error
 	}{
 		{
-			name:           "Hour test",
-			input:          []string{"1h", "Europe/Aten", "", "20210715T123456Z"},
-			expectedOutput: nil,
-			expectedError:  errors.New("unknown time zone Europe/Aten"),
+			name:  "Quarter hour test",
+			input: []string{"15m", "Europe/Athens", "20210714T204603Z", "20210714T220000Z"},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20210714T210000Z",
+					"20210714T211500Z",
+					"20210714T213000Z",
+					"20210714T214500Z",
+					"20210714T220000Z"},
+			},
+		},
+		{
+			name:  "Two hours test",
+			input: []string{"2h", "Europe/Athens", "20210714T204603Z", "20210715T123456Z"},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20210714T210000Z",
+					"20210714T230000Z",
+					"20210715T010000Z",
+					"20210715T030000Z",
+					"20210715T050000Z",
+					"20210715T070000Z",
+					"20210715T090000Z",
+					"20210715T110000Z"},
+			},
+		},
+		{
+			name:  "Three days test",
+			input: []string{"3d", "Europe/Athens", "20211010T204603Z", "20211031T123456Z"},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20211011T210000Z",
+					"20211014T210000Z",
+					"20211017T210000Z",
+					"20211020T210000Z",
+					"20211023T210000Z",
+					"20211026T210000Z",
+					"20211029T210000Z"},
+			},
+		},
+		{
+			name:  "Two months test",
+			input: []string{"2mo", "America/New_York", "20210214T204603Z", "20211115T123456Z"},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20210301T050000Z",
+					"20210501T040000Z",
+					"20210701T040000Z",
+					"20210901T040000Z",
+					"20211101T040000Z"},
+			},
+		},
+		{
+			name:  "Five years test",
+			input: []string{"5y", "Asia/Tokyo", "20210214T204603Z", "20371115T123456Z"},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20241231T150000Z",
+					"20291231T150000Z",
+					"20341231T150000Z"},
+			},
 		},
 	}
 
@@ -721,9 +778,2236 @@ func TestPtListUnhappyPath(t *testing.T) {
 
 			ptlist, err := srv.GetPtList(context.Background(), tc.input[0], tc.input[1], tc.input[2], tc.input[3])
 			if err != nil {
-				assert.Error(t, errors.New(err.Desc))
+				assert.NoError(t, errors.New(err.Desc))
+			}
+			require.Equal(t, tc.expectedOutput, ptlist)
+		})
+	}
+}
+
+func TestPtListHappyPathWeeksAndQuarters(t *testing.T) {
+	testcases := []struct {
+		name           string
+		input          []string
+		opts           []Option
+		expectedOutput *PtListResponse
+	}{
+		{
+			name:  "ISO week test",
+			input: []string{"1w", "Europe/Athens", "20211010T204603Z", "20211115T123456Z"},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20211010T210000Z",
+					"20211017T210000Z",
+					"20211024T210000Z",
+					"20211031T220000Z",
+					"20211107T220000Z",
+					"20211114T220000Z"},
+			},
+		},
+		{
+			name:  "Sunday week test",
+			input: []string{"1w", "America/New_York", "20211010T204603Z", "20211115T123456Z"},
+			opts:  []Option{WithWeekStart("sunday")},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20211017T040000Z",
+					"20211024T040000Z",
+					"20211031T040000Z",
+					"20211107T040000Z",
+					"20211114T050000Z"},
+			},
+		},
+		{
+			name:  "Two weeks test",
+			input: []string{"2w", "Europe/Stockholm", "20211010T204603Z", "20211215T123456Z"},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20211017T220000Z",
+					"20211031T230000Z",
+					"20211114T230000Z",
+					"20211128T230000Z",
+					"20211212T230000Z"},
+			},
+		},
+		{
+			name:  "Quarter test",
+			input: []string{"1q", "Europe/Athens", "20210214T204603Z", "20221115T123456Z"},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20210331T210000Z",
+					"20210630T210000Z",
+					"20210930T210000Z",
+					"20211231T220000Z",
+					"20220331T210000Z",
+					"20220630T210000Z",
+					"20220930T210000Z"},
+			},
+		},
+		{
+			name:  "Half year test",
+			input: []string{"2q", "Asia/Tokyo", "20210214T204603Z", "20231115T123456Z"},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20210630T150000Z",
+					"20211231T150000Z",
+					"20220630T150000Z",
+					"20221231T150000Z",
+					"20230630T150000Z"},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+
+			srv := NewService()
+
+			ptlist, err := srv.GetPtList(context.Background(), tc.input[0], tc.input[1], tc.input[2], tc.input[3], tc.opts...)
+			if err != nil {
+				assert.NoError(t, errors.New(err.Desc))
 			}
 			require.Equal(t, tc.expectedOutput, ptlist)
 		})
 	}
 }
+
+func TestPtListHappyPathYearStart(t *testing.T) {
+	testcases := []struct {
+		name           string
+		input          []string
+		opts           []Option
+		expectedOutput *PtListResponse
+	}{
+		{
+			name:  "Athens fiscal year test",
+			input: []string{"1y", "Europe/Athens", "20180214T204603Z", "20211115T123456Z"},
+			opts:  []Option{WithYearStart("04")},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20180331T210000Z",
+					"20190331T210000Z",
+					"20200331T210000Z",
+					"20210331T210000Z"},
+			},
+		},
+		{
+			name:  "Stockholm fiscal year test",
+			input: []string{"1y", "Europe/Stockholm", "20180214T204603Z", "20211115T123456Z"},
+			opts:  []Option{WithYearStart("07")},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20180630T220000Z",
+					"20190630T220000Z",
+					"20200630T220000Z",
+					"20210630T220000Z"},
+			},
+		},
+		{
+			name:  "Abidjan fiscal year test",
+			input: []string{"1y", "Africa/Abidjan", "20180214T204603Z", "20211115T123456Z"},
+			opts:  []Option{WithYearStart("04")},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20180401T000000Z",
+					"20190401T000000Z",
+					"20200401T000000Z",
+					"20210401T000000Z"},
+			},
+		},
+		{
+			name:  "New York fiscal year test",
+			input: []string{"1y", "America/New_York", "20180214T204603Z", "20211115T123456Z"},
+			opts:  []Option{WithYearStart("10")},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20181001T040000Z",
+					"20191001T040000Z",
+					"20201001T040000Z",
+					"20211001T040000Z"},
+			},
+		},
+		{
+			name:  "Tokyo fiscal year test",
+			input: []string{"1y", "Asia/Tokyo", "20180214T204603Z", "20211115T123456Z"},
+			opts:  []Option{WithYearStart("4")},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20180331T150000Z",
+					"20190331T150000Z",
+					"20200331T150000Z",
+					"20210331T150000Z"},
+			},
+		},
+		{
+			name:  "Mexico City fiscal year test",
+			input: []string{"1y", "America/Mexico_City", "20180214T204603Z", "20211115T123456Z"},
+			opts:  []Option{WithYearStart("07")},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20180701T050000Z",
+					"20190701T050000Z",
+					"20200701T050000Z",
+					"20210701T050000Z"},
+			},
+		},
+		{
+			name:  "Fiscal quarter test",
+			input: []string{"1q", "Europe/Athens", "20210214T204603Z", "20211115T123456Z"},
+			opts:  []Option{WithYearStart("02")},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20210430T210000Z",
+					"20210731T210000Z",
+					"20211031T220000Z"},
+			},
+		},
+		{
+			name:  "Two fiscal years test",
+			input: []string{"2y", "Europe/Athens", "20180214T204603Z", "20241115T123456Z"},
+			opts:  []Option{WithYearStart("04")},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20180331T210000Z",
+					"20200331T210000Z",
+					"20220331T210000Z",
+					"20240331T210000Z"},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+
+			srv := NewService()
+
+			ptlist, err := srv.GetPtList(context.Background(), tc.input[0], tc.input[1], tc.input[2], tc.input[3], tc.opts...)
+			if err != nil {
+				assert.NoError(t, errors.New(err.Desc))
+			}
+			require.Equal(t, tc.expectedOutput, ptlist)
+		})
+	}
+}
+
+func TestRRuleListHappyPath(t *testing.T) {
+	testcases := []struct {
+		name           string
+		input          []string
+		expectedOutput *PtListResponse
+	}{
+		{
+			name:  "BYDAY test",
+			input: []string{"FREQ=MONTHLY;BYDAY=-1FR;BYHOUR=9", "Europe/Athens", "20210101T000000Z", "20210630T000000Z"},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20210129T070000Z",
+					"20210226T070000Z",
+					"20210326T070000Z",
+					"20210430T060000Z",
+					"20210528T060000Z",
+					"20210625T060000Z"},
+			},
+		},
+		{
+			name:  "BYMONTHDAY test",
+			input: []string{"FREQ=MONTHLY;BYMONTHDAY=1,-1;BYHOUR=6", "America/New_York", "20210101T000000Z", "20210401T000000Z"},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20210101T110000Z",
+					"20210131T110000Z",
+					"20210201T110000Z",
+					"20210228T110000Z",
+					"20210301T110000Z",
+					"20210331T100000Z"},
+			},
+		},
+		{
+			name:  "BYSETPOS test",
+			input: []string{"FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1;BYHOUR=18", "Europe/Stockholm", "20210101T000000Z", "20210601T000000Z"},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20210129T170000Z",
+					"20210226T170000Z",
+					"20210331T160000Z",
+					"20210430T160000Z",
+					"20210531T160000Z"},
+			},
+		},
+		{
+			name:  "COUNT test",
+			input: []string{"DTSTART:20210104T083000\nRRULE:FREQ=WEEKLY;BYDAY=MO,WE;COUNT=5", "Asia/Tokyo", "20210101T000000Z", "20210601T000000Z"},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20210103T233000Z",
+					"20210105T233000Z",
+					"20210110T233000Z",
+					"20210112T233000Z",
+					"20210117T233000Z"},
+			},
+		},
+		{
+			name:  "UNTIL test",
+			input: []string{"FREQ=DAILY;BYHOUR=12;UNTIL=20210107T100000Z", "Europe/Athens", "20210101T000000Z", "20210601T000000Z"},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20210101T100000Z",
+					"20210102T100000Z",
+					"20210103T100000Z",
+					"20210104T100000Z",
+					"20210105T100000Z",
+					"20210106T100000Z",
+					"20210107T100000Z"},
+			},
+		},
+		{
+			name:  "EXDATE test",
+			input: []string{"DTSTART;TZID=America/New_York:20210312T090000\nRRULE:FREQ=DAILY;COUNT=5\nEXDATE:20210313T090000", "America/New_York", "20210301T000000Z", "20210401T000000Z"},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20210312T140000Z",
+					"20210314T130000Z",
+					"20210315T130000Z",
+					"20210316T130000Z"},
+			},
+		},
+		{
+			name:  "Leap day test",
+			input: []string{"FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=29", "Africa/Abidjan", "20150101T000000Z", "20250101T000000Z"},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20160229T000000Z",
+					"20200229T000000Z",
+					"20240229T000000Z"},
+			},
+		},
+		{
+			name:  "Old DTSTART test",
+			input: []string{"DTSTART:20200101T000000\nRRULE:FREQ=MINUTELY;INTERVAL=20", "UTC", "20230601T000000Z", "20230601T010000Z"},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20230601T000000Z",
+					"20230601T002000Z",
+					"20230601T004000Z",
+					"20230601T010000Z"},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+
+			srv := NewService()
+
+			ptlist, err := srv.GetRRuleList(context.Background(), tc.input[0], tc.input[1], tc.input[2], tc.input[3])
+			if err != nil {
+				assert.NoError(t, errors.New(err.Desc))
+			}
+			require.Equal(t, tc.expectedOutput, ptlist)
+		})
+	}
+}
+
+func TestRRuleListUnhappyPath(t *testing.T) {
+	testcases := []struct {
+		name          string
+		input         []string
+		expectedError error
+	}{
+		{
+			name:          "Unknown weekday test",
+			input:         []string{"FREQ=MONTHLY;BYDAY=XX", "Europe/Athens", "20210101T000000Z", "20210601T000000Z"},
+			expectedError: errors.New(`Could not parse recurrence rule: invalid BYDAY value "XX"`),
+		},
+		{
+			name:          "COUNT and UNTIL test",
+			input:         []string{"FREQ=DAILY;COUNT=2;UNTIL=20210107T100000Z", "Europe/Athens", "20210101T000000Z", "20210601T000000Z"},
+			expectedError: errors.New(`Could not parse recurrence rule: invalid COUNT value "2"`),
+		},
+		{
+			name:          "Missing FREQ test",
+			input:         []string{"BYHOUR=9", "Europe/Athens", "20210101T000000Z", "20210601T000000Z"},
+			expectedError: errors.New(`Could not parse recurrence rule: invalid FREQ value ""`),
+		},
+		{
+			name:          "Too many periods test",
+			input:         []string{"DTSTART:19000101T000000Z\nRRULE:FREQ=SECONDLY;BYHOUR=0;BYMINUTE=0;BYSECOND=0;COUNT=100000", "Europe/Athens", "20210101T000000Z", "20210102T000000Z"},
+			expectedError: errors.New("Time range spans too many timestamps: recurrence rule expands to too many periods"),
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+
+			srv := NewService()
+
+			ptlist, err := srv.GetRRuleList(context.Background(), tc.input[0], tc.input[1], tc.input[2], tc.input[3])
+			require.Nil(t, ptlist)
+			require.NotNil(t, err)
+			require.Equal(t, tc.expectedError.Error(), err.Desc)
+		})
+	}
+}
+
+func TestCronListHappyPath(t *testing.T) {
+	testcases := []struct {
+		name           string
+		input          []string
+		expectedOutput *PtListResponse
+	}{
+		{
+			name:  "Workday quarter hours test",
+			input: []string{"*/15 8-9 * * MON-FRI", "Europe/Athens", "20210716T000000Z", "20210719T070000Z"},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20210716T050000Z",
+					"20210716T051500Z",
+					"20210716T053000Z",
+					"20210716T054500Z",
+					"20210716T060000Z",
+					"20210716T061500Z",
+					"20210716T063000Z",
+					"20210716T064500Z",
+					"20210719T050000Z",
+					"20210719T051500Z",
+					"20210719T053000Z",
+					"20210719T054500Z",
+					"20210719T060000Z",
+					"20210719T061500Z",
+					"20210719T063000Z",
+					"20210719T064500Z"},
+			},
+		},
+		{
+			name:  "Daily across DST test",
+			input: []string{"30 2 * * *", "Europe/Athens", "20210326T000000Z", "20210330T000000Z"},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20210326T003000Z",
+					"20210327T003000Z",
+					"20210328T003000Z",
+					"20210328T233000Z",
+					"20210329T233000Z"},
+			},
+		},
+		{
+			name:  "Seconds field test",
+			input: []string{"0 0 12 1,15 * *", "America/New_York", "20210101T000000Z", "20210301T000000Z"},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20210101T170000Z",
+					"20210115T170000Z",
+					"20210201T170000Z",
+					"20210215T170000Z"},
+			},
+		},
+		{
+			name:  "Day of month or day of week test",
+			input: []string{"0 9 13 * FRI", "Asia/Tokyo", "20210801T000000Z", "20211001T000000Z"},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20210806T000000Z",
+					"20210813T000000Z",
+					"20210820T000000Z",
+					"20210827T000000Z",
+					"20210903T000000Z",
+					"20210910T000000Z",
+					"20210913T000000Z",
+					"20210917T000000Z",
+					"20210924T000000Z",
+					"20211001T000000Z"},
+			},
+		},
+		{
+			name:  "Macro test",
+			input: []string{"@monthly", "Africa/Abidjan", "20210101T000000Z", "20210401T000000Z"},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20210101T000000Z",
+					"20210201T000000Z",
+					"20210301T000000Z",
+					"20210401T000000Z"},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+
+			srv := NewService()
+
+			ptlist, err := srv.GetCronList(context.Background(), tc.input[0], tc.input[1], tc.input[2], tc.input[3])
+			if err != nil {
+				assert.NoError(t, errors.New(err.Desc))
+			}
+			require.Equal(t, tc.expectedOutput, ptlist)
+		})
+	}
+}
+
+func TestCronListUnhappyPath(t *testing.T) {
+	testcases := []struct {
+		name          string
+		input         []string
+		expectedError error
+	}{
+		{
+			name:          "Minute out of range test",
+			input:         []string{"61 * * * *", "Europe/Athens", "20210101T000000Z", "20210601T000000Z"},
+			expectedError: errors.New("Could not parse cron expression: invalid minute field \"61\""),
+		},
+		{
+			name:          "Missing field test",
+			input:         []string{"* * * *", "Europe/Athens", "20210101T000000Z", "20210601T000000Z"},
+			expectedError: errors.New("Could not parse cron expression: invalid expression field \"* * * *\""),
+		},
+		{
+			name:          "Hour range test",
+			input:         []string{"* 8-25 * * *", "Europe/Athens", "20210101T000000Z", "20210601T000000Z"},
+			expectedError: errors.New("Could not parse cron expression: invalid hour field \"8-25\""),
+		},
+		{
+			name:          "Month name test",
+			input:         []string{"* * * JANX *", "Europe/Athens", "20210101T000000Z", "20210601T000000Z"},
+			expectedError: errors.New("Could not parse cron expression: invalid month field \"JANX\""),
+		},
+		{
+			name:          "Zero step test",
+			input:         []string{"*/0 * * * *", "Europe/Athens", "20210101T000000Z", "20210601T000000Z"},
+			expectedError: errors.New("Could not parse cron expression: invalid minute field \"*/0\""),
+		},
+		{
+			name:          "Day of week range test",
+			input:         []string{"* * * * 1-8", "Europe/Athens", "20210101T000000Z", "20210601T000000Z"},
+			expectedError: errors.New("Could not parse cron expression: invalid day-of-week field \"1-8\""),
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+
+			srv := NewService()
+
+			ptlist, err := srv.GetCronList(context.Background(), tc.input[0], tc.input[1], tc.input[2], tc.input[3])
+			require.Nil(t, ptlist)
+			require.NotNil(t, err)
+			require.Equal(t, tc.expectedError.Error(), err.Desc)
+		})
+	}
+}
+
+// Europe/Athens moves from 03:00 to 04:00 on 2021-03-28 and from 04:00 back to 03:00 on 2021-10-31,
+// America/New_York from 02:00 to 03:00 on 2021-03-14 and from 02:00 back to 01:00 on 2021-11-07
+// and America/Santiago skips midnight on 2021-09-05.
+func TestPtListDSTPolicies(t *testing.T) {
+	testcases := []struct {
+		name           string
+		input          []string
+		dst            string
+		expectedOutput *PtListResponse
+	}{
+		{
+			name:  "Athens fall back default test",
+			input: []string{"1h", "Europe/Athens", "20211030T220000Z", "20211031T030000Z"},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20211030T220000Z",
+					"20211030T230000Z",
+					"20211031T000000Z",
+					"20211031T010000Z",
+					"20211031T020000Z",
+					"20211031T030000Z"},
+			},
+		},
+		{
+			name:  "Athens fall back earliest test",
+			input: []string{"1h", "Europe/Athens", "20211030T220000Z", "20211031T030000Z"},
+			dst:   "earliest",
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20211030T220000Z",
+					"20211030T230000Z",
+					"20211031T000000Z",
+					"20211031T020000Z",
+					"20211031T030000Z"},
+			},
+		},
+		{
+			name:  "Athens fall back latest test",
+			input: []string{"1h", "Europe/Athens", "20211030T220000Z", "20211031T030000Z"},
+			dst:   "latest",
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20211030T220000Z",
+					"20211030T230000Z",
+					"20211031T010000Z",
+					"20211031T020000Z",
+					"20211031T030000Z"},
+			},
+		},
+		{
+			name:  "Athens fall back quarter hours default test",
+			input: []string{"15m", "Europe/Athens", "20211030T233000Z", "20211031T013000Z"},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20211030T233000Z",
+					"20211030T234500Z",
+					"20211031T000000Z",
+					"20211031T001500Z",
+					"20211031T003000Z",
+					"20211031T004500Z",
+					"20211031T010000Z",
+					"20211031T011500Z",
+					"20211031T013000Z"},
+			},
+		},
+		{
+			name:  "Athens fall back repeated hour start test",
+			input: []string{"1h", "Europe/Athens", "20211031T003000Z", "20211031T013000Z"},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20211031T010000Z"},
+			},
+		},
+		{
+			name:  "New York spring forward default test",
+			input: []string{"1h", "America/New_York", "20210314T050000Z", "20210314T090000Z"},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20210314T050000Z",
+					"20210314T060000Z",
+					"20210314T070000Z",
+					"20210314T080000Z",
+					"20210314T090000Z"},
+			},
+		},
+		{
+			name:  "Santiago midnight gap skip test",
+			input: []string{"1d", "America/Santiago", "20210901T000000Z", "20210906T000000Z"},
+			dst:   "skip",
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20210901T040000Z",
+					"20210902T040000Z",
+					"20210903T040000Z",
+					"20210904T040000Z"},
+			},
+		},
+		{
+			name:  "Santiago midnight gap shift-forward test",
+			input: []string{"1d", "America/Santiago", "20210901T000000Z", "20210906T000000Z"},
+			dst:   "shift-forward",
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20210901T040000Z",
+					"20210902T040000Z",
+					"20210903T040000Z",
+					"20210904T040000Z",
+					"20210905T040000Z"},
+			},
+		},
+		{
+			name:  "Santiago midnight gap earliest test",
+			input: []string{"1d", "America/Santiago", "20210901T000000Z", "20210906T000000Z"},
+			dst:   "earliest",
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20210901T040000Z",
+					"20210902T040000Z",
+					"20210903T040000Z",
+					"20210904T040000Z",
+					"20210905T030000Z"},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+
+			srv := NewService()
+
+			ptlist, err := srv.GetPtList(context.Background(), tc.input[0], tc.input[1], tc.input[2], tc.input[3], WithDST(tc.dst))
+			if err != nil {
+				assert.NoError(t, errors.New(err.Desc))
+			}
+			require.Equal(t, tc.expectedOutput, ptlist)
+		})
+	}
+}
+
+func TestCronListDSTPolicies(t *testing.T) {
+	testcases := []struct {
+		name           string
+		input          []string
+		dst            string
+		expectedOutput *PtListResponse
+	}{
+		{
+			name:  "New York spring forward skip test",
+			input: []string{"30 2 * * *", "America/New_York", "20210312T000000Z", "20210316T000000Z"},
+			dst:   "skip",
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20210312T073000Z",
+					"20210313T073000Z",
+					"20210315T063000Z"},
+			},
+		},
+		{
+			name:  "New York spring forward shift-forward test",
+			input: []string{"30 2 * * *", "America/New_York", "20210312T000000Z", "20210316T000000Z"},
+			dst:   "shift-forward",
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20210312T073000Z",
+					"20210313T073000Z",
+					"20210314T073000Z",
+					"20210315T063000Z"},
+			},
+		},
+		{
+			name:  "New York spring forward earliest test",
+			input: []string{"30 2 * * *", "America/New_York", "20210312T000000Z", "20210316T000000Z"},
+			dst:   "earliest",
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20210312T073000Z",
+					"20210313T073000Z",
+					"20210314T063000Z",
+					"20210315T063000Z"},
+			},
+		},
+		{
+			name:  "New York fall back earliest test",
+			input: []string{"30 1 * * *", "America/New_York", "20211105T000000Z", "20211109T000000Z"},
+			dst:   "earliest",
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20211105T053000Z",
+					"20211106T053000Z",
+					"20211107T053000Z",
+					"20211108T063000Z"},
+			},
+		},
+		{
+			name:  "New York fall back latest test",
+			input: []string{"30 1 * * *", "America/New_York", "20211105T000000Z", "20211109T000000Z"},
+			dst:   "latest",
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20211105T053000Z",
+					"20211106T053000Z",
+					"20211107T063000Z",
+					"20211108T063000Z"},
+			},
+		},
+		{
+			name:  "New York fall back both test",
+			input: []string{"30 1 * * *", "America/New_York", "20211105T000000Z", "20211109T000000Z"},
+			dst:   "both",
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20211105T053000Z",
+					"20211106T053000Z",
+					"20211107T053000Z",
+					"20211107T063000Z",
+					"20211108T063000Z"},
+			},
+		},
+		{
+			name:  "Athens spring forward skip test",
+			input: []string{"30 3 * * *", "Europe/Athens", "20210327T000000Z", "20210330T000000Z"},
+			dst:   "skip",
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20210327T013000Z",
+					"20210329T003000Z"},
+			},
+		},
+		{
+			name:  "Athens fall back default test",
+			input: []string{"30 3 * * *", "Europe/Athens", "20211030T000000Z", "20211102T000000Z"},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20211030T003000Z",
+					"20211031T003000Z",
+					"20211031T013000Z",
+					"20211101T013000Z"},
+			},
+		},
+		{
+			name:  "Athens fall back half hours default test",
+			input: []string{"*/30 3 * * *", "Europe/Athens", "20211030T000000Z", "20211101T000000Z"},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20211030T000000Z",
+					"20211030T003000Z",
+					"20211031T000000Z",
+					"20211031T003000Z",
+					"20211031T010000Z",
+					"20211031T013000Z"},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+
+			srv := NewService()
+
+			ptlist, err := srv.GetCronList(context.Background(), tc.input[0], tc.input[1], tc.input[2], tc.input[3], WithDST(tc.dst))
+			if err != nil {
+				assert.NoError(t, errors.New(err.Desc))
+			}
+			require.Equal(t, tc.expectedOutput, ptlist)
+		})
+	}
+}
+
+// fakeClock always returns the same time.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c fakeClock) Now() time.Time {
+	return c.now
+}
+
+func TestPtListClockIndependence(t *testing.T) {
+	// Both sides of the Europe/Athens and America/New_York switches in spring and autumn 2021.
+	clocks := []fakeClock{
+		{now: time.Date(2021, 3, 13, 12, 0, 0, 0, time.UTC)},
+		{now: time.Date(2021, 3, 29, 12, 0, 0, 0, time.UTC)},
+		{now: time.Date(2021, 10, 30, 12, 0, 0, 0, time.UTC)},
+		{now: time.Date(2021, 11, 8, 12, 0, 0, 0, time.UTC)},
+	}
+
+	testcases := []struct {
+		name           string
+		input          []string
+		expectedOutput *PtListResponse
+	}{
+		{
+			name:  "Athens month test",
+			input: []string{"1mo", "Europe/Athens", "20210214T204603Z", "20210615T123456Z"},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20210228T220000Z",
+					"20210331T210000Z",
+					"20210430T210000Z",
+					"20210531T210000Z"},
+			},
+		},
+		{
+			name:  "Athens day test",
+			input: []string{"1d", "Europe/Athens", "20211029T204603Z", "20211102T123456Z"},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20211029T210000Z",
+					"20211030T210000Z",
+					"20211031T220000Z",
+					"20211101T220000Z"},
+			},
+		},
+		{
+			name:  "New York day test",
+			input: []string{"1d", "America/New_York", "20210312T204603Z", "20210316T123456Z"},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20210313T050000Z",
+					"20210314T050000Z",
+					"20210315T040000Z",
+					"20210316T040000Z"},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		for _, clock := range clocks {
+			t.Run(tc.name+" at "+clock.now.Format("20060102"), func(t *testing.T) {
+
+				srv := NewService(WithClock(clock))
+
+				ptlist, err := srv.GetPtList(context.Background(), tc.input[0], tc.input[1], tc.input[2], tc.input[3])
+				if err != nil {
+					assert.NoError(t, errors.New(err.Desc))
+				}
+				require.Equal(t, tc.expectedOutput, ptlist)
+			})
+		}
+	}
+
+	// Relative times read the injected clock, so the same request follows it across the switches.
+	expectedTodays := [][]string{
+		{"20210310T220000Z", "20210311T220000Z", "20210312T220000Z"},
+		{"20210326T220000Z", "20210327T220000Z", "20210328T210000Z"},
+		{"20211027T210000Z", "20211028T210000Z", "20211029T210000Z"},
+		{"20211105T220000Z", "20211106T220000Z", "20211107T220000Z"},
+	}
+	for i, clock := range clocks {
+		t.Run("Athens relative day test at "+clock.now.Format("20060102"), func(t *testing.T) {
+
+			srv := NewService(WithClock(clock))
+
+			ptlist, err := srv.GetPtList(context.Background(), "1d", "Europe/Athens", "today-2d", "today")
+			if err != nil {
+				assert.NoError(t, errors.New(err.Desc))
+			}
+			require.Equal(t, &PtListResponse{Timestamps: expectedTodays[i]}, ptlist)
+		})
+	}
+}
+
+func TestPtListRelativeTimes(t *testing.T) {
+	// 2021-11-03 12:20 in Europe/Athens.
+	clock := fakeClock{now: time.Date(2021, 11, 3, 10, 20, 0, 0, time.UTC)}
+
+	testcases := []struct {
+		name           string
+		input          []string
+		weekStart      string
+		expectedOutput *PtListResponse
+	}{
+		{
+			name:  "Last seven days test",
+			input: []string{"1d", "Europe/Athens", "now-7d", "now"},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20211027T210000Z",
+					"20211028T210000Z",
+					"20211029T210000Z",
+					"20211030T210000Z",
+					"20211031T220000Z",
+					"20211101T220000Z",
+					"20211102T220000Z"},
+			},
+		},
+		{
+			name:  "Today test",
+			input: []string{"1d", "Europe/Athens", "today-3d", "today"},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20211030T210000Z",
+					"20211031T220000Z",
+					"20211101T220000Z",
+					"20211102T220000Z"},
+			},
+		},
+		{
+			name:      "Start of last month test",
+			input:     []string{"1w", "America/New_York", "startofmonth-1mo", "now"},
+			weekStart: "sun",
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20211003T040000Z",
+					"20211010T040000Z",
+					"20211017T040000Z",
+					"20211024T040000Z",
+					"20211031T040000Z"},
+			},
+		},
+		{
+			name:  "Next hours test",
+			input: []string{"1h", "Asia/Tokyo", "now", "now+3h"},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20211103T110000Z",
+					"20211103T120000Z",
+					"20211103T130000Z"},
+			},
+		},
+		{
+			name:  "Start of week test",
+			input: []string{"1d", "Europe/Athens", "startofweek", "tomorrow"},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20211031T220000Z",
+					"20211101T220000Z",
+					"20211102T220000Z",
+					"20211103T220000Z"},
+			},
+		},
+		{
+			name:  "Absolute and relative test",
+			input: []string{"1d", "Europe/Athens", "20211101T000000Z", "yesterday"},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20211101T220000Z"},
+			},
+		},
+		{
+			name:           "Unknown unit test",
+			input:          []string{"1d", "Europe/Athens", "now-7x", "now"},
+			expectedOutput: nil,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+
+			srv := NewService(WithClock(clock))
+
+			ptlist, err := srv.GetPtList(context.Background(), tc.input[0], tc.input[1], tc.input[2], tc.input[3], WithWeekStart(tc.weekStart))
+			if tc.expectedOutput != nil && err != nil {
+				assert.NoError(t, errors.New(err.Desc))
+			}
+			require.Equal(t, tc.expectedOutput, ptlist)
+		})
+	}
+
+	offsetTestcases := []struct {
+		name          string
+		t1, t2        string
+		expectedParam string
+	}{
+		{name: "Overflowing offset test", t1: "now-2000000000000000000m", t2: "now", expectedParam: "t1"},
+		{name: "Offset past the maximum test", t1: "now", t2: "now+99999999999y", expectedParam: "t2"},
+		{name: "Offset past year 9999 test", t1: "now", t2: "now+999999y", expectedParam: "t2"},
+		{name: "Offset before year 1 test", t1: "startofyear-2021y", t2: "now", expectedParam: "t1"},
+	}
+
+	for _, tc := range offsetTestcases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := NewService(WithClock(clock))
+
+			ptlist, err := srv.GetPtList(context.Background(), "1y", "UTC", tc.t1, tc.t2)
+			require.Nil(t, ptlist)
+			require.NotNil(t, err)
+			require.True(t, errors.Is(err, pterrors.ErrTimeParsing))
+			require.Equal(t, tc.expectedParam, err.Param)
+		})
+	}
+}
+
+func TestPtListInputFormats(t *testing.T) {
+	testcases := []struct {
+		name           string
+		input          []string
+		inputFormat    string
+		expectedOutput *PtListResponse
+	}{
+		{
+			name:  "RFC 3339 test",
+			input: []string{"1h", "Europe/Athens", "2021-07-14T23:46:03+03:00", "2021-07-15T02:00:00+03:00"},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20210714T210000Z",
+					"20210714T220000Z",
+					"20210714T230000Z"},
+			},
+		},
+		{
+			name:  "ISO 8601 local test",
+			input: []string{"1h", "Europe/Athens", "2021-07-14T23:46:03", "2021-07-15T02:00"},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20210714T210000Z",
+					"20210714T220000Z",
+					"20210714T230000Z"},
+			},
+		},
+		{
+			name:  "Date test",
+			input: []string{"1d", "Europe/Athens", "2021-07-14", "20210716"},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20210713T210000Z",
+					"20210714T210000Z",
+					"20210715T210000Z"},
+			},
+		},
+		{
+			name:  "Epoch test",
+			input: []string{"1h", "Europe/Athens", "1626295563", "1626303600000"},
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20210714T210000Z",
+					"20210714T220000Z",
+					"20210714T230000Z"},
+			},
+		},
+		{
+			name:        "Explicit epoch seconds test",
+			input:       []string{"1h", "UTC", "0", "7200"},
+			inputFormat: "unix",
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"19700101T000000Z",
+					"19700101T010000Z",
+					"19700101T020000Z"},
+			},
+		},
+		{
+			name:        "Explicit format mismatch test",
+			input:       []string{"1h", "Europe/Athens", "20210714T204603Z", "now"},
+			inputFormat: "compact",
+		},
+		{
+			name:        "Unknown format test",
+			input:       []string{"1h", "Europe/Athens", "20210714T204603Z", "20210715T123456Z"},
+			inputFormat: "rfc2822",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+
+			srv := NewService()
+
+			ptlist, err := srv.GetPtList(context.Background(), tc.input[0], tc.input[1], tc.input[2], tc.input[3], WithInputFormat(tc.inputFormat))
+			if tc.expectedOutput != nil && err != nil {
+				assert.NoError(t, errors.New(err.Desc))
+			}
+			require.Equal(t, tc.expectedOutput, ptlist)
+		})
+	}
+}
+
+func TestPtListOutputFormats(t *testing.T) {
+	testcases := []struct {
+		name           string
+		outputFormat   string
+		local          bool
+		expectedOutput *PtListResponse
+	}{
+		{
+			name:         "RFC 3339 test",
+			outputFormat: "rfc3339",
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"2021-07-14T21:00:00Z",
+					"2021-07-14T22:00:00Z"},
+			},
+		},
+		{
+			name:         "RFC 3339 local test",
+			outputFormat: "rfc3339local",
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"2021-07-15T00:00:00+03:00",
+					"2021-07-15T01:00:00+03:00"},
+			},
+		},
+		{
+			name:         "Epoch seconds test",
+			outputFormat: "unix",
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"1626296400",
+					"1626300000"},
+			},
+		},
+		{
+			name:         "Epoch milliseconds test",
+			outputFormat: "unixms",
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"1626296400000",
+					"1626300000000"},
+			},
+		},
+		{
+			name:  "Local test",
+			local: true,
+			expectedOutput: &PtListResponse{
+				Timestamps: []string{
+					"20210714T210000Z",
+					"20210714T220000Z"},
+				Local: []string{
+					"2021-07-15T00:00:00+03:00",
+					"2021-07-15T01:00:00+03:00"},
+			},
+		},
+		{
+			name:         "Unknown format test",
+			outputFormat: "rfc2822",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+
+			srv := NewService()
+
+			ptlist, err := srv.GetPtList(context.Background(), "1h", "Europe/Athens", "20210714T204603Z", "20210714T223000Z", WithOutputFormat(tc.outputFormat), WithLocal(tc.local))
+			if tc.expectedOutput != nil && err != nil {
+				assert.NoError(t, errors.New(err.Desc))
+			}
+			require.Equal(t, tc.expectedOutput, ptlist)
+		})
+	}
+}
+
+func TestPtListIntervals(t *testing.T) {
+	testcases := []struct {
+		name           string
+		input          []string
+		expectedOutput *PtListResponse
+	}{
+		{
+			name:  "Day across DST end test",
+			input: []string{"1d", "Europe/Athens", "20211029T210000Z", "20211031T000000Z"},
+			expectedOutput: &PtListResponse{
+				Intervals: []Interval{
+					{Start: "20211029T210000Z", End: "20211030T210000Z", Label: "2021-10-30"},
+					{Start: "20211030T210000Z", End: "20211031T220000Z", Label: "2021-10-31"}},
+			},
+		},
+		{
+			name:  "Repeated hour test",
+			input: []string{"1h", "Europe/Athens", "20211030T233000Z", "20211031T013000Z"},
+			expectedOutput: &PtListResponse{
+				Intervals: []Interval{
+					{Start: "20211031T000000Z", End: "20211031T010000Z", Label: "2021-10-31T03:00+03:00"},
+					{Start: "20211031T010000Z", End: "20211031T020000Z", Label: "2021-10-31T03:00+02:00"}},
+			},
+		},
+		{
+			name:  "Year test",
+			input: []string{"1y", "Europe/Athens", "20201231T220000Z", "20210101T000000Z"},
+			expectedOutput: &PtListResponse{
+				Intervals: []Interval{
+					{Start: "20201231T220000Z", End: "20211231T220000Z", Label: "2021"}},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+
+			srv := NewService()
+
+			ptlist, err := srv.GetPtList(context.Background(), tc.input[0], tc.input[1], tc.input[2], tc.input[3], WithMode("intervals"))
+			if err != nil {
+				assert.NoError(t, errors.New(err.Desc))
+			}
+			require.Equal(t, tc.expectedOutput, ptlist)
+		})
+	}
+
+	t.Run("Cron intervals test", func(t *testing.T) {
+		srv := NewService()
+
+		ptlist, err := srv.GetCronList(context.Background(), "@daily", "Europe/Athens", "20210714T204603Z", "20210715T123456Z", WithMode("intervals"))
+		require.Nil(t, ptlist)
+		require.Equal(t, "Unsupported output mode", err.Desc)
+	})
+
+	// Santiago skips midnight on 2021-09-05, the interval before ends at the end of its day.
+	t.Run("Skipped day test", func(t *testing.T) {
+		srv := NewService()
+
+		ptlist, err := srv.GetPtList(context.Background(), "1d", "America/Santiago", "20210904T040000Z", "20210906T030000Z", WithMode("intervals"), WithDST("skip"))
+		require.Nil(t, err)
+		require.Equal(t, &PtListResponse{
+			Intervals: []Interval{
+				{Start: "20210904T040000Z", End: "20210905T040000Z", Label: "2021-09-04"},
+				{Start: "20210906T030000Z", End: "20210907T030000Z", Label: "2021-09-06"}},
+		}, ptlist)
+	})
+
+	t.Run("Local intervals test", func(t *testing.T) {
+		srv := NewService()
+
+		ptlist, err := srv.GetPtList(context.Background(), "1d", "Europe/Athens", "20211029T210000Z", "20211031T000000Z", WithMode("intervals"), WithLocal(true))
+		require.Nil(t, ptlist)
+		require.Equal(t, "Unsupported output mode", err.Desc)
+		require.Equal(t, "local", err.Param)
+	})
+}
+
+func TestPtListUnhappyPath(t *testing.T) {
+	testcases := []struct {
+		name           string
+		input          []string
+		expectedOutput *PtListResponse
+		expectedError  error
+	}{
+		{
+			name:           "Hour test",
+			input:          []string{"1h", "Europe/Aten", "", "20210715T123456Z"},
+			expectedOutput: nil,
+			expectedError:  errors.New("unknown time zone Europe/Aten"),
+		},
+		{
+			name:           "Zero period test",
+			input:          []string{"0h", "Europe/Athens", "20210714T204603Z", "20210715T123456Z"},
+			expectedOutput: nil,
+			expectedError:  errors.New("Unsupported period"),
+		},
+		{
+			name:           "Unknown unit test",
+			input:          []string{"15s", "Europe/Athens", "20210714T204603Z", "20210715T123456Z"},
+			expectedOutput: nil,
+			expectedError:  errors.New("Unsupported period"),
+		},
+		{
+			name:           "Huge period test",
+			input:          []string{"9007199254740992m", "Europe/Athens", "20210714T204603Z", "20210715T123456Z"},
+			expectedOutput: nil,
+			expectedError:  errors.New("Unsupported period"),
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+
+			srv := NewService()
+
+			ptlist, err := srv.GetPtList(context.Background(), tc.input[0], tc.input[1], tc.input[2], tc.input[3])
+			if err != nil {
+				assert.Error(t, errors.New(err.Desc))
+			}
+			require.Equal(t, tc.expectedOutput, ptlist)
+		})
+	}
+}
+
+func TestPtListErrors(t *testing.T) {
+	srv := NewService()
+
+	t.Run("Unknown timezone test", func(t *testing.T) {
+		_, err := srv.GetPtList(context.Background(), "1h", "Europe/Aten", "20210714T204603Z", "20210715T123456Z")
+		require.True(t, errors.Is(err, pterrors.ErrUnknownTimezone))
+		require.False(t, errors.Is(err, pterrors.ErrUnsupportedPeriod))
+		require.EqualError(t, errors.Unwrap(err), "unknown time zone Europe/Aten")
+	})
+
+	t.Run("Cron cause test", func(t *testing.T) {
+		_, err := srv.GetCronList(context.Background(), "61 * * * *", "Europe/Athens", "20210714T204603Z", "20210715T123456Z")
+		require.True(t, errors.Is(err, pterrors.ErrCronParsing))
+
+		var parseErr *cron.ParseError
+		require.True(t, errors.As(err, &parseErr))
+		require.Equal(t, "minute", parseErr.Field)
+	})
+
+	t.Run("Sentinel test", func(t *testing.T) {
+		require.Equal(t, "Could not load given timezone location", pterrors.ErrUnknownTimezone.Error())
+		require.True(t, errors.Is(pterrors.GetError(pterrors.TimezoneLoadingError), pterrors.ErrUnknownTimezone))
+		require.True(t, errors.Is(pterrors.GetError(pterrors.TimezoneLoadingError), pterrors.GetError(pterrors.TimezoneLoadingError)))
+	})
+}
+
+func TestPtListValidate(t *testing.T) {
+	testcases := []struct {
+		name           string
+		req            PtListRequest
+		opts           []Option
+		expectedCode   int
+		expectedParams []string
+	}{
+		{
+			name: "Valid test",
+			req:  PtListRequest{Period: "1h", TZ: "Europe/Athens", T1: "20210714T204603Z", T2: "20210715T123456Z"},
+		},
+		{
+			name:           "Single error test",
+			req:            PtListRequest{Period: "1h", TZ: "Europe/Aten", T1: "20210714T204603Z", T2: "20210715T123456Z"},
+			expectedCode:   pterrors.TimezoneLoadingError,
+			expectedParams: []string{"tz"},
+		},
+		{
+			name:           "All errors test",
+			req:            PtListRequest{Period: "15s", TZ: "Europe/Aten", T1: "20210714T204603Z", T2: "tomorrow-1x"},
+			expectedCode:   pterrors.ValidationError,
+			expectedParams: []string{"period", "tz", "t2"},
+		},
+		{
+			name:           "Ordering test",
+			req:            PtListRequest{Period: "1h", TZ: "Europe/Athens", T1: "20210715T123456Z", T2: "20210714T204603Z"},
+			expectedCode:   pterrors.InvalidRange,
+			expectedParams: []string{"t2"},
+		},
+		{
+			name:           "Range limit test",
+			req:            PtListRequest{Period: "1m", TZ: "Europe/Athens", T1: "20210101T000000Z", T2: "20210715T000000Z"},
+			expectedCode:   pterrors.RangeTooLarge,
+			expectedParams: []string{"t2"},
+		},
+		{
+			name:           "Cron and options test",
+			req:            PtListRequest{Cron: "61 * * * *", TZ: "Europe/Athens", T1: "20210714T204603Z", T2: "20210715T123456Z"},
+			opts:           []Option{WithDST("never"), WithMode("intervals")},
+			expectedCode:   pterrors.ValidationError,
+			expectedParams: []string{"cron", "dst", "mode"},
+		},
+		{
+			name:           "Local intervals test",
+			req:            PtListRequest{Period: "1h", TZ: "Europe/Athens", T1: "20210714T204603Z", T2: "20210715T123456Z"},
+			opts:           []Option{WithMode("intervals"), WithLocal(true)},
+			expectedCode:   pterrors.UnsupportedMode,
+			expectedParams: []string{"local"},
+		},
+		{
+			name:           "Huge period test",
+			req:            PtListRequest{Period: "9007199254740992m", TZ: "Europe/Athens", T1: "20210714T204603Z", T2: "20210715T123456Z"},
+			expectedCode:   pterrors.UnsupportedPeriod,
+			expectedParams: []string{"period"},
+		},
+		{
+			name: "Longest periods test",
+			req:  PtListRequest{Period: "1000000w", TZ: "Europe/Athens", T1: "00010101T000000Z", T2: "99991231T000000Z"},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+
+			srv := NewService(WithRangeLimit(100000))
+
+			err := srv.Validate(context.Background(), tc.req, tc.opts...)
+			if tc.expectedCode == 0 {
+				require.Nil(t, err)
+				return
+			}
+
+			require.Equal(t, tc.expectedCode, err.Code)
+			params := []string{err.Param}
+			if len(err.Errors) > 0 {
+				params = nil
+				for _, fieldErr := range err.Errors {
+					params = append(params, fieldErr.Param)
+					require.True(t, errors.Is(err, fieldErr))
+				}
+			}
+			require.Equal(t, tc.expectedParams, params)
+		})
+	}
+}
+
+func TestPtListBatch(t *testing.T) {
+	queries := []BatchQuery{
+		{PtListRequest: PtListRequest{Period: "1h", TZ: "Europe/Athens", T1: "20210714T204603Z", T2: "20210714T223000Z"}},
+		{PtListRequest: PtListRequest{Period: "1h", TZ: "Europe/Aten", T1: "20210714T204603Z", T2: "20210714T223000Z"}},
+		{PtListRequest: PtListRequest{Cron: "0 0 * * *", TZ: "Europe/Athens", T1: "20210714T204603Z", T2: "20210716T000000Z"}, OutputFormat: "unix"},
+	}
+
+	t.Run("Ordered results test", func(t *testing.T) {
+		srv := NewService(WithBatchWorkers(2))
+
+		results, err := srv.GetBatch(context.Background(), queries)
+		require.Nil(t, err)
+		require.Len(t, results, 3)
+		require.Equal(t, &PtListResponse{Timestamps: []string{"20210714T210000Z", "20210714T220000Z"}}, results[0].Result)
+		require.Nil(t, results[1].Result)
+		require.True(t, errors.Is(results[1].Error, pterrors.ErrUnknownTimezone))
+		require.Equal(t, &PtListResponse{Timestamps: []string{"1626296400", "1626382800"}}, results[2].Result)
+	})
+
+	t.Run("Canceled test", func(t *testing.T) {
+		srv := NewService()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		results, err := srv.GetBatch(ctx, queries)
+		require.Nil(t, err)
+		for _, result := range results {
+			require.True(t, errors.Is(result.Error, pterrors.ErrRequestCanceled))
+			require.True(t, errors.Is(result.Error, context.Canceled))
+		}
+	})
+
+	t.Run("Canceled query test", func(t *testing.T) {
+		srv := NewService()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// A query that has already started stops listing once the context is done.
+		_, err := srv.List(ctx, queries[0].PtListRequest)
+		require.True(t, errors.Is(err, pterrors.ErrRequestCanceled))
+	})
+
+	t.Run("Batch entries test", func(t *testing.T) {
+		srv := NewService(WithBatchWorkers(1), WithBatchEntries(3))
+
+		results, err := srv.GetBatch(context.Background(), queries)
+		require.Nil(t, err)
+		require.Equal(t, &PtListResponse{Timestamps: []string{"20210714T210000Z", "20210714T220000Z"}}, results[0].Result)
+		require.True(t, errors.Is(results[1].Error, pterrors.ErrUnknownTimezone))
+		require.Nil(t, results[2].Result)
+		require.True(t, errors.Is(results[2].Error, pterrors.ErrResultTooLarge))
+	})
+
+	t.Run("Batch entries order test", func(t *testing.T) {
+		srv := NewService(WithBatchWorkers(8), WithBatchEntries(100))
+
+		// A long query last, the limit is reserved in query order whichever query finishes first.
+		many := make([]BatchQuery, 0, 41)
+		for i := 0; i < 40; i++ {
+			many = append(many, BatchQuery{PtListRequest: PtListRequest{Period: "1h", TZ: "UTC", T1: "20210714T000000Z", T2: "20210714T020000Z"}})
+		}
+		many = append(many, BatchQuery{PtListRequest: PtListRequest{Period: "1m", TZ: "UTC", T1: "20210714T000000Z", T2: "20210714T060000Z"}})
+
+		for run := 0; run < 20; run++ {
+			results, err := srv.GetBatch(context.Background(), many)
+			require.Nil(t, err)
+			for i, result := range results {
+				if i < 33 {
+					require.Nil(t, result.Error, "query %d", i)
+					require.Len(t, result.Result.Timestamps, 3)
+					continue
+				}
+				require.Nil(t, result.Result, "query %d", i)
+				require.True(t, errors.Is(result.Error, pterrors.ErrResultTooLarge), "query %d", i)
+			}
+		}
+	})
+
+	t.Run("Batch limit test", func(t *testing.T) {
+		srv := NewService(WithBatchLimit(2))
+
+		_, err := srv.GetBatch(context.Background(), queries)
+		require.True(t, errors.Is(err, pterrors.ErrBatchTooLarge))
+	})
+}
+
+func TestPtListPagination(t *testing.T) {
+	srv := NewService(WithMaxLimit(5))
+
+	// Pages concatenate to the full list, across the repeated hour of a DST end as well.
+	pages := map[string]func(opts ...Option) (*PtListResponse, *pterrors.ErrResp){
+		"Period": func(opts ...Option) (*PtListResponse, *pterrors.ErrResp) {
+			return srv.GetPtList(context.Background(), "1h", "Europe/Athens", "20211030T220000Z", "20211031T030000Z", opts...)
+		},
+		"Cron": func(opts ...Option) (*PtListResponse, *pterrors.ErrResp) {
+			return srv.GetCronList(context.Background(), "0 * * * *", "Europe/Athens", "20211030T220000Z", "20211031T030000Z", opts...)
+		},
+		"RRule": func(opts ...Option) (*PtListResponse, *pterrors.ErrResp) {
+			return srv.GetRRuleList(context.Background(), "FREQ=HOURLY", "Europe/Athens", "20211030T220000Z", "20211031T030000Z", opts...)
+		},
+	}
+	for name, page := range pages {
+		t.Run(name+" pages test", func(t *testing.T) {
+			var timestamps []string
+			cursor := ""
+			for i := 0; i < 3; i++ {
+				resp, err := page(WithLimit("2"), WithCursor(cursor))
+				require.Nil(t, err)
+				timestamps = append(timestamps, resp.Timestamps...)
+				cursor = resp.NextCursor
+			}
+			require.Empty(t, cursor)
+			require.Equal(t, []string{
+				"20211030T220000Z",
+				"20211030T230000Z",
+				"20211031T000000Z",
+				"20211031T010000Z",
+				"20211031T020000Z",
+				"20211031T030000Z"}, timestamps)
+		})
+	}
+
+	t.Run("Intervals pages test", func(t *testing.T) {
+		resp, err := srv.GetPtList(context.Background(), "1d", "Europe/Athens", "20211028T210000Z", "20211031T000000Z", WithMode("intervals"), WithLimit("2"))
+		require.Nil(t, err)
+		require.Equal(t, []Interval{
+			{Start: "20211028T210000Z", End: "20211029T210000Z", Label: "2021-10-29"},
+			{Start: "20211029T210000Z", End: "20211030T210000Z", Label: "2021-10-30"}}, resp.Intervals)
+		require.NotEmpty(t, resp.NextCursor)
+
+		resp, err = srv.GetPtList(context.Background(), "1d", "Europe/Athens", "20211028T210000Z", "20211031T000000Z", WithMode("intervals"), WithLimit("2"), WithCursor(resp.NextCursor))
+		require.Nil(t, err)
+		require.Equal(t, &PtListResponse{Intervals: []Interval{
+			{Start: "20211030T210000Z", End: "20211031T220000Z", Label: "2021-10-31"}}}, resp)
+	})
+
+	t.Run("Limit above maximum test", func(t *testing.T) {
+		_, err := srv.GetPtList(context.Background(), "1h", "Europe/Athens", "20211030T220000Z", "20211031T030000Z", WithLimit("6"))
+		require.True(t, errors.Is(err, pterrors.ErrInvalidLimit))
+	})
+
+	t.Run("Unbounded test", func(t *testing.T) {
+		_, err := srv.GetPtList(context.Background(), "1h", "Europe/Athens", "20211030T220000Z", "20211031T030000Z")
+		require.True(t, errors.Is(err, pterrors.ErrResultTooLarge))
+	})
+
+	t.Run("Other query cursor test", func(t *testing.T) {
+		resp, err := srv.GetPtList(context.Background(), "1h", "Europe/Athens", "20211030T220000Z", "20211031T030000Z", WithLimit("2"))
+		require.Nil(t, err)
+		require.NotEmpty(t, resp.NextCursor)
+
+		_, err = srv.GetPtList(context.Background(), "15m", "Europe/Athens", "20211030T220000Z", "20211031T030000Z", WithLimit("2"), WithCursor(resp.NextCursor))
+		require.True(t, errors.Is(err, pterrors.ErrInvalidCursor))
+		require.Equal(t, "cursor", err.Param)
+
+		err = srv.Validate(context.Background(), PtListRequest{Period: "1h", TZ: "UTC", T1: "20211030T220000Z", T2: "20211031T030000Z"}, WithLimit("2"), WithCursor(resp.NextCursor))
+		require.True(t, errors.Is(err, pterrors.ErrInvalidCursor))
+	})
+
+	t.Run("Invalid cursor test", func(t *testing.T) {
+		_, err := srv.GetPtList(context.Background(), "1h", "Europe/Athens", "20211030T220000Z", "20211031T030000Z", WithLimit("2"), WithCursor("not a cursor"))
+		require.True(t, errors.Is(err, pterrors.ErrInvalidCursor))
+		require.Equal(t, "cursor", err.Param)
+	})
+}
+
+func TestPtListStream(t *testing.T) {
+	req := PtListRequest{Period: "1h", TZ: "Europe/Athens", T1: "20210714T204603Z", T2: "20210715T000000Z"}
+
+	t.Run("Entries test", func(t *testing.T) {
+		srv := NewService(WithMaxLimit(2))
+
+		var entries []Entry
+		err := srv.Stream(context.Background(), req, func(entry Entry) bool {
+			entries = append(entries, entry)
+			return true
+		}, WithLocal(true))
+		require.Nil(t, err)
+		require.Equal(t, []Entry{
+			{Timestamp: "20210714T210000Z", Local: "2021-07-15T00:00:00+03:00"},
+			{Timestamp: "20210714T220000Z", Local: "2021-07-15T01:00:00+03:00"},
+			{Timestamp: "20210714T230000Z", Local: "2021-07-15T02:00:00+03:00"},
+			{Timestamp: "20210715T000000Z", Local: "2021-07-15T03:00:00+03:00"}}, entries)
+	})
+
+	t.Run("Stop test", func(t *testing.T) {
+		srv := NewService()
+
+		var entries []Entry
+		err := srv.Stream(context.Background(), req, func(entry Entry) bool {
+			entries = append(entries, entry)
+			return len(entries) < 2
+		})
+		require.Nil(t, err)
+		require.Len(t, entries, 2)
+	})
+
+	t.Run("Canceled test", func(t *testing.T) {
+		srv := NewService()
+		ctx, cancel := context.WithCancel(context.Background())
+
+		var entries []Entry
+		err := srv.Stream(ctx, req, func(entry Entry) bool {
+			entries = append(entries, entry)
+			cancel()
+			return true
+		})
+		require.True(t, errors.Is(err, pterrors.ErrRequestCanceled))
+		require.Len(t, entries, 1)
+	})
+}
+
+func TestPtListCount(t *testing.T) {
+	srv := NewService(WithMaxLimit(1 << 30))
+
+	timezones := []string{"Europe/Athens", "Europe/Stockholm", "Africa/Abidjan", "America/New_York", "America/Santiago", "America/Mexico_City", "Asia/Tokyo", "Australia/Lord_Howe", "Pacific/Apia"}
+	periods := []string{"15m", "1h", "2h", "1d", "3d", "1w", "1mo", "1q", "1y"}
+	ranges := [][]string{
+		{"20210714T204603Z", "20210715T123456Z"},
+		{"20210101T000000Z", "20220101T000000Z"},
+		{"20111225T000000Z", "20120105T000000Z"},
+		{"20180214T204603Z", "20211115T123456Z"},
+	}
+	policies := []string{"skip", "shift-forward", "earliest", "latest", "both"}
+
+	for _, tz := range timezones {
+		for _, period := range periods {
+			for _, r := range ranges {
+				// Keep the test fast, sub-hourly periods are counted over the shorter ranges.
+				if period == "15m" && r[0] == "20180214T204603Z" {
+					continue
+				}
+				for _, policy := range policies {
+					ptlist, err := srv.GetPtList(context.Background(), period, tz, r[0], r[1], WithDST(policy))
+					require.Nil(t, err)
+
+					count, err := srv.Count(context.Background(), period, tz, r[0], r[1], WithDST(policy))
+					require.Nil(t, err)
+					require.Equal(t, len(ptlist.Timestamps), count.Count, "%s %s %s-%s %s", period, tz, r[0], r[1], policy)
+				}
+			}
+		}
+	}
+
+	// Ranges and steps longer than a time.Duration, and transitions far in the future.
+	longRanges := []struct {
+		period string
+		t1     string
+		t2     string
+	}{
+		{period: "1d", t1: "16000101T000000Z", t2: "20000101T000000Z"},
+		{period: "1000000m", t1: "00010101T000000Z", t2: "99991231T000000Z"},
+		{period: "1000000h", t1: "00010101T000000Z", t2: "99991231T000000Z"},
+		{period: "1000000d", t1: "00010101T000000Z", t2: "99991231T000000Z"},
+		{period: "1000000w", t1: "00010101T000000Z", t2: "99991231T000000Z"},
+		{period: "1d", t1: "20400101T000000Z", t2: "20450101T000000Z"},
+	}
+	for _, r := range longRanges {
+		ptlist, err := srv.GetPtList(context.Background(), r.period, "Europe/Athens", r.t1, r.t2)
+		require.Nil(t, err)
+
+		count, err := srv.Count(context.Background(), r.period, "Europe/Athens", r.t1, r.t2)
+		require.Nil(t, err)
+		require.Equal(t, len(ptlist.Timestamps), count.Count, "%s %s-%s", r.period, r.t1, r.t2)
+	}
+
+	t.Run("Ordering test", func(t *testing.T) {
+		count, err := srv.Count(context.Background(), "1h", "Europe/Athens", "20210715T123456Z", "20210714T204603Z")
+		require.Nil(t, count)
+		require.Equal(t, pterrors.InvalidRange, err.Code)
+		require.Equal(t, "t2", err.Param)
+	})
+
+	t.Run("All errors test", func(t *testing.T) {
+		count, err := srv.Count(context.Background(), "15s", "Europe/Aten", "20210714T204603Z", "tomorrow-1x")
+		require.Nil(t, count)
+		require.Equal(t, pterrors.ValidationError, err.Code)
+		require.Len(t, err.Errors, 3)
+	})
+
+	t.Run("Unlimited range test", func(t *testing.T) {
+		srv := NewService(WithRangeLimit(100000))
+
+		count, err := srv.Count(context.Background(), "1m", "Europe/Athens", "20210101T000000Z", "20210715T000000Z")
+		require.Nil(t, err)
+		require.Equal(t, 280801, count.Count)
+	})
+}
+
+func TestPtListNextAndPrev(t *testing.T) {
+	srv := NewService(WithMaxLimit(1 << 30))
+
+	t.Run("Next test", func(t *testing.T) {
+		ptlist, err := srv.Next(context.Background(), "1h", "Europe/Athens", "20211031T003000Z", 3)
+		require.Nil(t, err)
+		require.Equal(t, []string{"20211031T010000Z", "20211031T020000Z", "20211031T030000Z"}, ptlist.Timestamps)
+	})
+
+	t.Run("Next from boundary test", func(t *testing.T) {
+		ptlist, err := srv.Next(context.Background(), "1mo", "Europe/Athens", "20211031T220000Z", 2, WithOutputFormat("rfc3339local"))
+		require.Nil(t, err)
+		require.Equal(t, []string{"2021-12-01T00:00:00+02:00", "2022-01-01T00:00:00+02:00"}, ptlist.Timestamps)
+	})
+
+	t.Run("Prev test", func(t *testing.T) {
+		ptlist, err := srv.Prev(context.Background(), "1h", "Europe/Athens", "20211031T013000Z", 3)
+		require.Nil(t, err)
+		require.Equal(t, []string{"20211030T230000Z", "20211031T000000Z", "20211031T010000Z"}, ptlist.Timestamps)
+	})
+
+	t.Run("Prev from now test", func(t *testing.T) {
+		srv := NewService(WithClock(fakeClock{now: time.Date(2021, 11, 3, 10, 20, 0, 0, time.UTC)}))
+
+		ptlist, err := srv.Prev(context.Background(), "1w", "Europe/Athens", "", 1, WithMode("intervals"))
+		require.Nil(t, err)
+		require.Equal(t, []Interval{{Start: "20211031T220000Z", End: "20211107T220000Z", Label: "2021-11-01"}}, ptlist.Intervals)
+	})
+
+	t.Run("Long period test", func(t *testing.T) {
+		ptlist, err := srv.Prev(context.Background(), "1000000h", "UTC", "20210101T000000Z", 2)
+		require.Nil(t, err)
+		require.Equal(t, []string{"18551203T080000Z", "19700101T000000Z"}, ptlist.Timestamps)
+
+		ptlist, err = srv.Next(context.Background(), "1000000h", "UTC", "18551203T080000Z", 2)
+		require.Nil(t, err)
+		require.Equal(t, []string{"19700101T000000Z", "20840129T160000Z"}, ptlist.Timestamps)
+	})
+
+	t.Run("First years test", func(t *testing.T) {
+		ptlist, err := srv.Prev(context.Background(), "1y", "UTC", "00030101T000000Z", 10)
+		require.Nil(t, err)
+		require.Equal(t, []string{"00010101T000000Z", "00020101T000000Z"}, ptlist.Timestamps)
+
+		ptlist, err = srv.Prev(context.Background(), "1y", "UTC", "00010101T000000Z", 10)
+		require.Nil(t, err)
+		require.Equal(t, []string{}, ptlist.Timestamps)
+	})
+
+	t.Run("Invalid number test", func(t *testing.T) {
+		_, err := srv.Next(context.Background(), "1h", "Europe/Athens", "20211031T003000Z", 0)
+		require.True(t, errors.Is(err, pterrors.ErrInvalidNumber))
+		require.Equal(t, "n", err.Param)
+	})
+
+	t.Run("All errors test", func(t *testing.T) {
+		_, err := srv.Prev(context.Background(), "15s", "Europe/Aten", "tomorrow-1x", 0, WithDST("never"), WithOutputFormat("nonsense"))
+		require.Equal(t, pterrors.ValidationError, err.Code)
+		params := []string{}
+		for _, fieldErr := range err.Errors {
+			params = append(params, fieldErr.Param)
+		}
+		require.Equal(t, []string{"period", "dst", "tz", "from", "output_format", "n"}, params)
+	})
+
+	// Next and previous timestamps match the list around them, across DST transitions as well.
+	for _, tz := range []string{"Europe/Athens", "America/Santiago", "Australia/Lord_Howe"} {
+		for _, period := range []string{"15m", "1h", "1d", "1mo"} {
+			for _, policy := range []string{"skip", "shift-forward", "earliest", "latest", "both"} {
+				ptlist, err := srv.GetPtList(context.Background(), period, tz, "20210101T000000Z", "20220101T000000Z", WithDST(policy))
+				require.Nil(t, err)
+				timestamps := ptlist.Timestamps
+
+				for _, i := range []int{2, len(timestamps) / 4, len(timestamps) / 2, len(timestamps) * 3 / 4} {
+					next, err := srv.Next(context.Background(), period, tz, timestamps[i-1], 2, WithDST(policy))
+					require.Nil(t, err)
+					require.Equal(t, timestamps[i:i+2], next.Timestamps, "%s %s %s next %s", period, tz, policy, timestamps[i-1])
+
+					prev, err := srv.Prev(context.Background(), period, tz, timestamps[i], 2, WithDST(policy))
+					require.Nil(t, err)
+					require.Equal(t, timestamps[i-2:i], prev.Timestamps, "%s %s %s prev %s", period, tz, policy, timestamps[i])
+				}
+			}
+		}
+	}
+}
+
+func TestPtListContains(t *testing.T) {
+	srv := NewService()
+
+	t.Run("Contains test", func(t *testing.T) {
+		resp, err := srv.Contains(context.Background(), "15m", "Europe/Athens", "20211031T003000Z")
+		require.Nil(t, err)
+		require.Equal(t, &ContainsResponse{Contains: true, Prev: "20211031T001500Z", Next: "20211031T004500Z"}, resp)
+	})
+
+	t.Run("Not contains test", func(t *testing.T) {
+		resp, err := srv.Contains(context.Background(), "1d", "Europe/Athens", "20211031T000000Z", WithOutputFormat("rfc3339local"))
+		require.Nil(t, err)
+		require.Equal(t, &ContainsResponse{Contains: false, Prev: "2021-10-31T00:00:00+03:00", Next: "2021-11-01T00:00:00+02:00"}, resp)
+	})
+
+	t.Run("First year test", func(t *testing.T) {
+		resp, err := srv.Contains(context.Background(), "1y", "UTC", "00010101T000000Z")
+		require.Nil(t, err)
+		require.Equal(t, &ContainsResponse{Contains: true, Next: "00020101T000000Z"}, resp)
+	})
+
+	t.Run("Invalid timestamp test", func(t *testing.T) {
+		_, err := srv.Contains(context.Background(), "1h", "Europe/Athens", "yesterdayish")
+		require.True(t, errors.Is(err, pterrors.ErrTimeParsing))
+		require.Equal(t, "ts", err.Param)
+	})
+
+	t.Run("All errors test", func(t *testing.T) {
+		_, err := srv.Contains(context.Background(), "15s", "Europe/Aten", "yesterdayish", WithOutputFormat("nonsense"))
+		require.Equal(t, pterrors.ValidationError, err.Code)
+		params := []string{}
+		for _, fieldErr := range err.Errors {
+			params = append(params, fieldErr.Param)
+		}
+		require.Equal(t, []string{"period", "tz", "ts", "output_format"}, params)
+	})
+
+	// Timestamps of a list and the points between them match the list, across DST transitions as well.
+	ranges := map[string][2]string{
+		"Europe/Athens":       {"20211030T000000Z", "20211101T000000Z"},
+		"America/Santiago":    {"20210903T000000Z", "20210906T000000Z"},
+		"Australia/Lord_Howe": {"20210402T000000Z", "20210405T000000Z"},
+	}
+	for tz, r := range ranges {
+		for _, period := range []string{"15m", "1h", "1d"} {
+			for _, policy := range []string{"skip", "shift-forward", "earliest", "latest", "both"} {
+				ptlist, err := srv.GetPtList(context.Background(), period, tz, r[0], r[1], WithDST(policy))
+				require.Nil(t, err)
+				timestamps := ptlist.Timestamps
+
+				for i := 1; i < len(timestamps)-1; i++ {
+					resp, err := srv.Contains(context.Background(), period, tz, timestamps[i], WithDST(policy))
+					require.Nil(t, err)
+					require.Equal(t, &ContainsResponse{Contains: true, Prev: timestamps[i-1], Next: timestamps[i+1]}, resp, "%s %s %s %s", period, tz, policy, timestamps[i])
+
+					timeObj, _ := time.Parse(utils.CompactLayout, timestamps[i])
+					timeObj = timeObj.Add(time.Minute)
+					resp, err = srv.Contains(context.Background(), period, tz, timeObj.Format(utils.CompactLayout), WithDST(policy))
+					require.Nil(t, err)
+					require.Equal(t, &ContainsResponse{Contains: false, Prev: timestamps[i], Next: timestamps[i+1]}, resp, "%s %s %s %s", period, tz, policy, timeObj)
+				}
+			}
+		}
+	}
+}
+
+func TestPtListBucket(t *testing.T) {
+	srv := NewService(WithMaxLimit(1 << 30))
+
+	t.Run("Bucket test", func(t *testing.T) {
+		resp, err := srv.Bucket(context.Background(), "1d", "Europe/Athens", []string{"20211030T200000Z", "20211030T230000Z", "20211031T210000Z", "20211031T230000Z"})
+		require.Nil(t, err)
+		require.Equal(t, &BucketResponse{Buckets: []Bucket{
+			{Timestamp: "20211030T200000Z", Interval: &Interval{Start: "20211029T210000Z", End: "20211030T210000Z", Label: "2021-10-30"}},
+			{Timestamp: "20211030T230000Z", Interval: &Interval{Start: "20211030T210000Z", End: "20211031T220000Z", Label: "2021-10-31"}},
+			{Timestamp: "20211031T210000Z", Interval: &Interval{Start: "20211030T210000Z", End: "20211031T220000Z", Label: "2021-10-31"}},
+			{Timestamp: "20211031T230000Z", Interval: &Interval{Start: "20211031T220000Z", End: "20211101T220000Z", Label: "2021-11-01"}},
+		}}, resp)
+	})
+
+	t.Run("Skipped day test", func(t *testing.T) {
+		resp, err := srv.Bucket(context.Background(), "1d", "America/Santiago", []string{"20210905T030000Z", "20210905T120000Z", "20210906T030000Z"}, WithDST("skip"))
+		require.Nil(t, err)
+		require.Equal(t, &BucketResponse{Buckets: []Bucket{
+			{Timestamp: "20210905T030000Z", Interval: &Interval{Start: "20210904T040000Z", End: "20210905T040000Z", Label: "2021-09-04"}},
+			{Timestamp: "20210905T120000Z"},
+			{Timestamp: "20210906T030000Z", Interval: &Interval{Start: "20210906T030000Z", End: "20210907T030000Z", Label: "2021-09-06"}},
+		}}, resp)
+	})
+
+	t.Run("First year test", func(t *testing.T) {
+		resp, err := srv.Bucket(context.Background(), "1y", "America/New_York", []string{"00010101T000000Z", "00010102T000000Z"})
+		require.Nil(t, err)
+		require.Equal(t, &BucketResponse{Buckets: []Bucket{
+			{Timestamp: "00010101T000000Z"},
+			{Timestamp: "00010102T000000Z", Interval: &Interval{Start: "00010101T045602Z", End: "00020101T045602Z", Label: "0001"}},
+		}}, resp)
+	})
+
+	t.Run("Invalid timestamps test", func(t *testing.T) {
+		_, err := srv.Bucket(context.Background(), "1d", "Europe/Athens", []string{"20211030T200000Z", "x", "y"})
+		require.True(t, errors.Is(err, pterrors.ErrValidation))
+		require.Len(t, err.Errors, 2)
+		require.Equal(t, "timestamps[1]", err.Errors[0].Param)
+		require.Equal(t, "timestamps[2]", err.Errors[1].Param)
+	})
+
+	t.Run("All errors test", func(t *testing.T) {
+		_, err := srv.Bucket(context.Background(), "15s", "Europe/Aten", []string{"20211030T200000Z", "x"}, WithDST("never"), WithOutputFormat("nonsense"))
+		require.Equal(t, pterrors.ValidationError, err.Code)
+		params := []string{}
+		for _, fieldErr := range err.Errors {
+			params = append(params, fieldErr.Param)
+		}
+		require.Equal(t, []string{"period", "dst", "tz", "output_format", "timestamps[1]"}, params)
+	})
+
+	t.Run("Too many timestamps test", func(t *testing.T) {
+		srv := NewService(WithMaxPoints(1))
+
+		_, err := srv.Bucket(context.Background(), "1d", "Europe/Athens", []string{"20211030T200000Z", "20211030T230000Z"})
+		require.True(t, errors.Is(err, pterrors.ErrBodyTooLarge))
+		require.Equal(t, "timestamps", err.Param)
+	})
+
+	t.Run("Consecutive minutes test", func(t *testing.T) {
+		// 100000 minutes across the DST end of 2021, every second one in its middle.
+		ptlist, err := srv.GetPtList(context.Background(), "1m", "Europe/Athens", "20211001T000000Z", "20211209T104000Z", WithMode("intervals"))
+		require.Nil(t, err)
+		require.Len(t, ptlist.Intervals, 100001)
+
+		timestamps := make([]string, 0, 100000)
+		expected := make([]Bucket, 0, 100000)
+		for i := range ptlist.Intervals[:100000] {
+			timestamp := ptlist.Intervals[i].Start
+			if i%2 == 1 {
+				timestamp = timestamp[:13] + "30Z"
+			}
+			timestamps = append(timestamps, timestamp)
+			expected = append(expected, Bucket{Timestamp: timestamp, Interval: &ptlist.Intervals[i]})
+		}
+
+		begin := time.Now()
+		resp, err := srv.Bucket(context.Background(), "1m", "Europe/Athens", timestamps)
+		require.Nil(t, err)
+		require.Less(t, time.Since(begin), 10*time.Second)
+		require.Equal(t, expected, resp.Buckets)
+	})
+
+	// Buckets match the intervals of the list, across DST transitions as well.
+	for _, tz := range []string{"Europe/Athens", "America/Santiago", "Australia/Lord_Howe"} {
+		for _, period := range []string{"15m", "1h", "1d", "1mo"} {
+			for _, policy := range []string{"skip", "shift-forward", "earliest", "latest", "both"} {
+				ptlist, err := srv.GetPtList(context.Background(), period, tz, "20210101T000000Z", "20220101T000000Z", WithDST(policy), WithMode("intervals"))
+				require.Nil(t, err)
+
+				var timestamps []string
+				var expected []Bucket
+				for i := 0; i < len(ptlist.Intervals); i += 1 + i/8 {
+					interval := ptlist.Intervals[i]
+					start, _ := time.Parse(utils.CompactLayout, interval.Start)
+					end, _ := time.Parse(utils.CompactLayout, interval.End)
+					for _, timeObj := range []time.Time{start, start.Add(end.Sub(start) / 2), end.Add(-time.Second)} {
+						timestamp := timeObj.Format(utils.CompactLayout)
+						timestamps = append(timestamps, timestamp)
+						expected = append(expected, Bucket{Timestamp: timestamp, Interval: &ptlist.Intervals[i]})
+					}
+				}
+
+				resp, err := srv.Bucket(context.Background(), period, tz, timestamps, WithDST(policy))
+				require.Nil(t, err)
+				require.Equal(t, expected, resp.Buckets, "%s %s %s", period, tz, policy)
+			}
+		}
+	}
+}
+
+func TestPtListGaps(t *testing.T) {
+	srv := NewService()
+	req := PtListRequest{Period: "1h", TZ: "Europe/Athens", T1: "20211031T000000Z", T2: "20211031T050000Z"}
+
+	t.Run("Gaps test", func(t *testing.T) {
+		observed := []string{
+			"20211030T230000Z",
+			"20211031T000000Z",
+			"20211031T010000Z",
+			"20211031T010000Z",
+			"20211031T013000Z",
+			"20211031T013000Z",
+			"20211031T040000Z",
+			"20211031T060000Z"}
+		resp, err := srv.GetGaps(context.Background(), req, ReadSlice(observed))
+		require.Nil(t, err)
+		require.Equal(t, &GapsResponse{
+			Missing:     []string{"20211031T020000Z", "20211031T030000Z", "20211031T050000Z"},
+			OffSchedule: []string{"20211030T230000Z", "20211031T013000Z", "20211031T060000Z"},
+			Duplicates:  []string{"20211031T010000Z", "20211031T013000Z"},
+		}, resp)
+	})
+
+	t.Run("No gaps test", func(t *testing.T) {
+		ptlist, err := srv.GetPtList(context.Background(), "15m", "Europe/Athens", "20211030T000000Z", "20211101T000000Z")
+		require.Nil(t, err)
+
+		resp, err := srv.GetGaps(context.Background(), PtListRequest{Period: "15m", TZ: "Europe/Athens", T1: "20211030T000000Z", T2: "20211101T000000Z"}, ReadSlice(ptlist.Timestamps))
+		require.Nil(t, err)
+		require.Equal(t, &GapsResponse{Missing: []string{}, OffSchedule: []string{}, Duplicates: []string{}}, resp)
+	})
+
+	t.Run("Cron gaps test", func(t *testing.T) {
+		observed := []string{"2021-07-14T09:00:00+03:00", "2021-07-16T09:00:00+03:00"}
+		req := PtListRequest{Cron: "0 9 * * *", TZ: "Europe/Athens", T1: "20210714T000000Z", T2: "20210717T000000Z"}
+		resp, err := srv.GetGaps(context.Background(), req, ReadSlice(observed), WithOutputFormat("rfc3339local"))
+		require.Nil(t, err)
+		require.Equal(t, []string{"2021-07-15T09:00:00+03:00"}, resp.Missing)
+	})
+
+	t.Run("Relative observations test", func(t *testing.T) {
+		srv := NewService(WithClock(fakeClock{now: time.Date(2021, 10, 31, 2, 30, 0, 0, time.UTC)}))
+
+		// Observations are read like t1 and t2, relative expressions included.
+		resp, err := srv.GetGaps(context.Background(), req, ReadSlice([]string{"startofday", "now-30m", "20211031T030000Z"}))
+		require.Nil(t, err)
+		require.Equal(t, &GapsResponse{
+			Missing:     []string{"20211031T000000Z", "20211031T010000Z", "20211031T040000Z", "20211031T050000Z"},
+			OffSchedule: []string{"20211030T210000Z"},
+			Duplicates:  []string{},
+		}, resp)
+	})
+
+	t.Run("Unsorted test", func(t *testing.T) {
+		_, err := srv.GetGaps(context.Background(), req, ReadSlice([]string{"20211031T010000Z", "20211031T000000Z"}))
+		require.True(t, errors.Is(err, pterrors.ErrUnsortedTimestamps))
+		require.Equal(t, "timestamps[1]", err.Param)
+	})
+
+	t.Run("Invalid timestamp test", func(t *testing.T) {
+		_, err := srv.GetGaps(context.Background(), req, ReadSlice([]string{"20211031T010000Z", "x"}))
+		require.True(t, errors.Is(err, pterrors.ErrTimeParsing))
+		require.Equal(t, "timestamps[1]", err.Param)
+	})
+
+	t.Run("Reader error test", func(t *testing.T) {
+		_, err := srv.GetGaps(context.Background(), req, func() (string, bool, error) {
+			return "", false, errors.New("unexpected EOF")
+		})
+		require.True(t, errors.Is(err, pterrors.ErrInvalidRequestBody))
+	})
+
+	t.Run("Validation test", func(t *testing.T) {
+		_, err := srv.GetGaps(context.Background(), PtListRequest{Period: "1x", TZ: "Europe/Aten"}, ReadSlice(nil))
+		require.True(t, errors.Is(err, pterrors.ErrValidation))
+	})
+
+	t.Run("Stop test", func(t *testing.T) {
+		var gaps []Gap
+		err := srv.Gaps(context.Background(), req, ReadSlice(nil), func(gap Gap) bool {
+			gaps = append(gaps, gap)
+			return len(gaps) < 2
+		})
+		require.Nil(t, err)
+		require.Equal(t, []Gap{{Kind: GapMissing, Timestamp: "20211031T000000Z"}, {Kind: GapMissing, Timestamp: "20211031T010000Z"}}, gaps)
+	})
+}
+
+func TestPtListAggregate(t *testing.T) {
+	srv := NewService()
+	points := []Point{
+		{Timestamp: "20211030T203000Z", Value: 100},
+		{Timestamp: "20211031T120000Z", Value: 4},
+		{Timestamp: "20211030T220000Z", Value: 2},
+		{Timestamp: "20211031T213000Z", Value: 6},
+		{Timestamp: "20211102T120000Z", Value: 1},
+		{Timestamp: "20211103T120000Z", Value: 100},
+	}
+	value := func(v float64) *float64 {
+		return &v
+	}
+	full := Aggregate{
+		Interval: Interval{Start: "20211030T210000Z", End: "20211031T220000Z", Label: "2021-10-31"},
+		Count:    3,
+		Sum:      value(12),
+		Avg:      value(4),
+		Min:      value(2),
+		Max:      value(6),
+		First:    value(2),
+		Last:     value(6),
+	}
+	single := Aggregate{
+		Interval: Interval{Start: "20211101T220000Z", End: "20211102T220000Z", Label: "2021-11-02"},
+		Count:    1,
+		Sum:      value(1),
+		Avg:      value(1),
+		Min:      value(1),
+		Max:      value(1),
+		First:    value(1),
+		Last:     value(1),
+	}
+	empty := Interval{Start: "20211031T220000Z", End: "20211101T220000Z", Label: "2021-11-01"}
+
+	testcases := []struct {
+		name     string
+		fill     string
+		expected Aggregate
+	}{
+		{
+			name:     "Null fill test",
+			expected: Aggregate{Interval: empty},
+		},
+		{
+			name:     "Zero fill test",
+			fill:     "zero",
+			expected: Aggregate{Interval: empty, Sum: value(0), Avg: value(0), Min: value(0), Max: value(0), First: value(0), Last: value(0)},
+		},
+		{
+			name:     "Carry forward fill test",
+			fill:     "carry-forward",
+			expected: Aggregate{Interval: empty, Sum: value(12), Avg: value(4), Min: value(2), Max: value(6), First: value(2), Last: value(6)},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := srv.Aggregate(context.Background(), "1d", "Europe/Athens", "20211030T210000Z", "20211101T220000Z", points, WithFill(tc.fill))
+			require.Nil(t, err)
+			require.Equal(t, &AggregateResponse{Periods: []Aggregate{full, tc.expected, single}}, resp)
+		})
+	}
+
+	t.Run("Unsupported fill test", func(t *testing.T) {
+		_, err := srv.Aggregate(context.Background(), "1d", "Europe/Athens", "20211030T210000Z", "20211101T220000Z", points, WithFill("linear"))
+		require.True(t, errors.Is(err, pterrors.ErrUnsupportedFillPolicy))
+		require.Equal(t, "fill", err.Param)
+	})
+
+	t.Run("Invalid timestamp test", func(t *testing.T) {
+		_, err := srv.Aggregate(context.Background(), "1d", "Europe/Athens", "20211030T210000Z", "20211101T220000Z", []Point{{Timestamp: "x"}})
+		require.True(t, errors.Is(err, pterrors.ErrTimeParsing))
+		require.Equal(t, "points[0].timestamp", err.Param)
+	})
+
+	t.Run("Too many points test", func(t *testing.T) {
+		srv := NewService(WithMaxPoints(2))
+
+		_, err := srv.Aggregate(context.Background(), "1d", "Europe/Athens", "20211030T210000Z", "20211101T220000Z", points)
+		require.True(t, errors.Is(err, pterrors.ErrBodyTooLarge))
+		require.Equal(t, "points", err.Param)
+	})
+
+	t.Run("Too many periods test", func(t *testing.T) {
+		srv := NewService(WithMaxLimit(48))
+
+		resp, err := srv.Aggregate(context.Background(), "1h", "UTC", "20211030T000000Z", "20211031T230000Z", nil)
+		require.Nil(t, err)
+		require.Len(t, resp.Periods, 48)
+
+		_, err = srv.Aggregate(context.Background(), "1h", "UTC", "20211030T000000Z", "20211101T000000Z", nil)
+		require.True(t, errors.Is(err, pterrors.ErrResultTooLarge))
+		require.Equal(t, "t2", err.Param)
+	})
+}
+
+func TestPtListResample(t *testing.T) {
+	srv := NewService()
+	points := []Point{
+		{Timestamp: "20210714T002000Z", Value: 20},
+		{Timestamp: "20210714T000000Z", Value: 0},
+		{Timestamp: "20210714T011000Z", Value: 70},
+	}
+	value := func(v float64) *float64 {
+		return &v
+	}
+
+	testcases := []struct {
+		name     string
+		method   string
+		maxGap   string
+		expected []*float64
+	}{
+		{
+			name:     "Previous test",
+			expected: []*float64{value(0), value(0), value(20), value(20), value(20)},
+		},
+		{
+			name:     "Previous max gap test",
+			method:   "previous",
+			maxGap:   "30m",
+			expected: []*float64{value(0), value(0), value(20), value(20), nil},
+		},
+		{
+			name:     "Nearest test",
+			method:   "nearest",
+			expected: []*float64{value(0), value(20), value(20), value(20), value(70)},
+		},
+		{
+			name:     "Nearest max gap test",
+			method:   "nearest",
+			maxGap:   "20m",
+			expected: []*float64{value(0), value(20), value(20), nil, value(70)},
+		},
+		{
+			name:     "Linear test",
+			method:   "linear",
+			expected: []*float64{value(0), value(15), value(30), value(45), value(60)},
+		},
+		{
+			name:     "Linear max gap test",
+			method:   "linear",
+			maxGap:   "30m",
+			expected: []*float64{value(0), value(15), nil, nil, nil},
+		},
+		{
+			name:     "Time weighted test",
+			method:   "time-weighted",
+			expected: []*float64{value(0), value(12000.0 / 900), value(20), value(20), value(33000.0 / 900)},
+		},
+		{
+			name:     "Time weighted max gap test",
+			method:   "time-weighted",
+			maxGap:   "30m",
+			expected: []*float64{value(0), value(12000.0 / 900), value(20), value(20), value(70)},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := srv.Resample(context.Background(), "15m", "UTC", "20210714T000000Z", "20210714T010000Z", points, WithMethod(tc.method), WithMaxGap(tc.maxGap))
+			require.Nil(t, err)
+			require.Equal(t, &ResampleResponse{Samples: []Sample{
+				{Timestamp: "20210714T000000Z", Value: tc.expected[0]},
+				{Timestamp: "20210714T001500Z", Value: tc.expected[1]},
+				{Timestamp: "20210714T003000Z", Value: tc.expected[2]},
+				{Timestamp: "20210714T004500Z", Value: tc.expected[3]},
+				{Timestamp: "20210714T010000Z", Value: tc.expected[4]},
+			}}, resp)
+		})
+	}
+
+	t.Run("Empty series test", func(t *testing.T) {
+		resp, err := srv.Resample(context.Background(), "1h", "UTC", "20210714T000000Z", "20210714T010000Z", nil, WithMethod("linear"))
+		require.Nil(t, err)
+		require.Equal(t, &ResampleResponse{Samples: []Sample{{Timestamp: "20210714T000000Z"}, {Timestamp: "20210714T010000Z"}}}, resp)
+	})
+
+	t.Run("Invalid options test", func(t *testing.T) {
+		_, err := srv.Resample(context.Background(), "1h", "UTC", "20210714T000000Z", "20210714T010000Z", points, WithMethod("cubic"), WithMaxGap("-5m"))
+		require.True(t, errors.Is(err, pterrors.ErrValidation))
+		require.True(t, errors.Is(err, pterrors.ErrUnsupportedMethod))
+		require.True(t, errors.Is(err, pterrors.ErrInvalidMaxGap))
+	})
+
+	t.Run("Too many timestamps test", func(t *testing.T) {
+		srv := NewService(WithMaxLimit(4))
+
+		_, err := srv.Resample(context.Background(), "15m", "UTC", "20210714T000000Z", "20210714T010000Z", points)
+		require.True(t, errors.Is(err, pterrors.ErrResultTooLarge))
+		require.Equal(t, "t2", err.Param)
+	})
+}
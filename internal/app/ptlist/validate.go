@@ -0,0 +1,101 @@
+package ptlist
+
+import (
+	"context"
+	"plist/errors"
+	"plist/utils"
+)
+
+// DefaultRangeLimit is the default maximum number of timestamps a period list may span.
+const DefaultRangeLimit = 1000000
+
+// shortestUnits are the shortest lengths of period units in seconds, a day across a daylight
+// saving transition lasts 23 hours.
+var shortestUnits = map[string]int64{
+	utils.Minute:  60,
+	utils.Hour:    60 * 60,
+	utils.Day:     23 * 60 * 60,
+	utils.Week:    (7*24 - 1) * 60 * 60,
+	utils.Month:   (28*24 - 1) * 60 * 60,
+	utils.Quarter: (89*24 - 1) * 60 * 60,
+	utils.Year:    (365*24 - 1) * 60 * 60,
+}
+
+// WithRangeLimit sets the maximum number of timestamps a validated period list may span.
+func WithRangeLimit(limit int) ServiceOption {
+	return func(s *Service) {
+		s.rangeLimit = limit
+	}
+}
+
+// Validate checks every parameter of a request and reports all field errors together: the
+// schedule, the options, tz, t1, t2, their order and, for period lists, that the range spans no
+// more timestamps than the range limit of the service. A single field error is returned as is.
+func (s *Service) Validate(ctx context.Context, req PtListRequest, opts ...Option) *errors.ErrResp {
+	_, errResp := s.validate(req, newOptions(opts), s.rangeLimit)
+	return errResp
+}
+
+// validate checks a request like Validate, with the given range limit or none when 0, and returns
+// it parsed when it is valid.
+func (s *Service) validate(req PtListRequest, o *options, rangeLimit int) (*query, *errors.ErrResp) {
+	q, errResps := s.parseQuery(req, o)
+	errResps = append(errResps, s.checkOptions(req, o)...)
+
+	// The range is counted in whole seconds, time.Duration saturates after 292 years.
+	if q.ranged {
+		var errResp *errors.ErrResp
+		switch {
+		case q.timeObj1UTC.After(q.timeObj2UTC):
+			errResp = errors.GetError(errors.InvalidRange)
+		case q.period != nil && rangeLimit > 0 && (q.timeObj2UTC.Unix()-q.timeObj1UTC.Unix())/shortestUnits[q.period.Unit]/int64(q.period.N) >= int64(rangeLimit):
+			errResp = errors.GetError(errors.RangeTooLarge)
+		}
+		if errResp != nil {
+			errResp.Param = "t2"
+			errResps = append(errResps, errResp)
+		}
+	}
+
+	if len(errResps) > 0 {
+		return nil, errors.Join(errResps)
+	}
+	return q, nil
+}
+
+// checkOptions checks the output, mode, resampling and paging options of a request and returns
+// every field error.
+func (s *Service) checkOptions(req PtListRequest, o *options) []*errors.ErrResp {
+	var errResps []*errors.ErrResp
+	fail := func(errResp *errors.ErrResp, param string) {
+		errResp.Param = param
+		errResps = append(errResps, errResp)
+	}
+
+	if _, errResp := utils.ParseOutputFormat(o.output); errResp != nil {
+		fail(errResp, "output_format")
+	}
+	if o.mode != "" && o.mode != ModePoints && (o.mode != ModeIntervals || req.RRule != "" || req.Cron != "") {
+		fail(errors.GetError(errors.UnsupportedMode), "mode")
+	}
+	if o.mode == ModeIntervals && o.local {
+		fail(errors.GetError(errors.UnsupportedMode), "local")
+	}
+
+	if _, errResp := parseFill(o.fill); errResp != nil {
+		fail(errResp, "fill")
+	}
+
+	if _, errResp := parseMethod(o.method); errResp != nil {
+		fail(errResp, "method")
+	}
+	if _, errResp := parseMaxGap(o.maxGap); errResp != nil {
+		fail(errResp, "max_gap")
+	}
+
+	if _, _, errResp := o.page(s.maxLimit, queryKey(req, o)); errResp != nil {
+		errResps = append(errResps, errResp)
+	}
+
+	return errResps
+}
@@ -0,0 +1,230 @@
+// Package cron parses and expands standard cron expressions.
+//
+// Both the 5-field form (minute hour day-of-month month day-of-week) and the 6-field form with
+// a leading seconds field are supported. Like package rrule, expansion works on wall-clock
+// times represented as UTC time objects.
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseError reports the field of the expression that could not be parsed.
+type ParseError struct {
+	Field string
+	Value string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("invalid %s field %q", e.Field, e.Value)
+}
+
+type field struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
+
+var (
+	secondField = field{name: "second", min: 0, max: 59}
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day-of-month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12, names: map[string]int{
+		"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+		"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+	}}
+	// Both 0 and 7 are Sunday.
+	dowField = field{name: "day-of-week", min: 0, max: 7, names: map[string]int{
+		"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+	}}
+)
+
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Schedule is a parsed cron expression.
+type Schedule struct {
+	seconds []bool
+	minutes []bool
+	hours   []bool
+	doms    []bool
+	months  []bool
+	dows    []bool
+
+	// A day matches when either day field matches if both are restricted, as in Vixie cron,
+	// where a field starting with * is unrestricted, e.g. */15.
+	domRestricted bool
+	dowRestricted bool
+}
+
+// Parse parses a 5 or 6 field cron expression, or one of the @yearly, @monthly, @weekly,
+// @daily and @hourly macros.
+func Parse(expr string) (*Schedule, error) {
+	if macro, ok := macros[strings.ToLower(strings.TrimSpace(expr))]; ok {
+		expr = macro
+	}
+
+	fields := strings.Fields(expr)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, &ParseError{Field: "expression", Value: expr}
+	}
+
+	s := &Schedule{}
+	var err error
+	if s.seconds, _, err = parseField(fields[0], secondField); err != nil {
+		return nil, err
+	}
+	if s.minutes, _, err = parseField(fields[1], minuteField); err != nil {
+		return nil, err
+	}
+	if s.hours, _, err = parseField(fields[2], hourField); err != nil {
+		return nil, err
+	}
+	if s.doms, s.domRestricted, err = parseField(fields[3], domField); err != nil {
+		return nil, err
+	}
+	if s.months, _, err = parseField(fields[4], monthField); err != nil {
+		return nil, err
+	}
+	if s.dows, s.dowRestricted, err = parseField(fields[5], dowField); err != nil {
+		return nil, err
+	}
+	s.dows[0] = s.dows[0] || s.dows[7]
+
+	return s, nil
+}
+
+// Iterate calls fn with every matching wall-clock time between start and end, both inclusive,
+// in order until fn returns false.
+func (s *Schedule) Iterate(start, end time.Time, fn func(time.Time) bool) {
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	for ; !day.After(end); day = day.AddDate(0, 0, 1) {
+		if !s.matchesDay(day) {
+			continue
+		}
+
+		for hour := 0; hour < 24; hour++ {
+			if !s.hours[hour] {
+				continue
+			}
+			for minute := 0; minute < 60; minute++ {
+				if !s.minutes[minute] {
+					continue
+				}
+				for second := 0; second < 60; second++ {
+					if !s.seconds[second] {
+						continue
+					}
+
+					t := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, second, 0, time.UTC)
+					if t.Before(start) {
+						continue
+					}
+					if t.After(end) || !fn(t) {
+						return
+					}
+				}
+			}
+		}
+	}
+}
+
+func (s *Schedule) matchesDay(day time.Time) bool {
+	if !s.months[day.Month()] {
+		return false
+	}
+
+	dom := s.doms[day.Day()]
+	dow := s.dows[day.Weekday()]
+	if s.domRestricted && s.dowRestricted {
+		return dom || dow
+	}
+	return dom && dow
+}
+
+// parseField parses a comma separated list of values, ranges and steps, e.g. */15, 8-18 or MON-FRI.
+// It also reports whether the field restricts its values, i.e. it does not start with a wildcard.
+func parseField(value string, f field) ([]bool, bool, error) {
+	matches := make([]bool, f.max+1)
+	restricted := !strings.HasPrefix(value, "*") && !strings.HasPrefix(value, "?")
+
+	for _, part := range strings.Split(value, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n <= 0 {
+				return nil, false, &ParseError{Field: f.name, Value: value}
+			}
+			step = n
+		}
+
+		var low, high int
+		switch {
+		case rangePart == "*" || rangePart == "?":
+			low, high = f.min, f.max
+			if f.name == dowField.name {
+				high = 6
+			}
+		case strings.Contains(rangePart, "-"):
+			lowPart, highPart, _ := strings.Cut(rangePart, "-")
+			var err error
+			if low, err = parseValue(lowPart, f); err != nil {
+				return nil, false, &ParseError{Field: f.name, Value: value}
+			}
+			if high, err = parseValue(highPart, f); err != nil {
+				return nil, false, &ParseError{Field: f.name, Value: value}
+			}
+			if low > high {
+				return nil, false, &ParseError{Field: f.name, Value: value}
+			}
+		default:
+			var err error
+			if low, err = parseValue(rangePart, f); err != nil {
+				return nil, false, &ParseError{Field: f.name, Value: value}
+			}
+			high = low
+			if hasStep {
+				high = f.max
+			}
+		}
+
+		for i := low; i <= high; i += step {
+			matches[i] = true
+		}
+	}
+
+	return matches, restricted, nil
+}
+
+func parseValue(value string, f field) (int, error) {
+	if n, ok := f.names[strings.ToUpper(value)]; ok {
+		return n, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, err
+	}
+	if n < f.min || n > f.max {
+		return 0, fmt.Errorf("%d out of range", n)
+	}
+	return n, nil
+}
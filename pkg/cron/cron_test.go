@@ -0,0 +1,149 @@
+package cron
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRestrictedDays(t *testing.T) {
+	testcases := []struct {
+		name                  string
+		expr                  string
+		expectedDOMRestricted bool
+		expectedDOWRestricted bool
+	}{
+		{name: "Wildcards", expr: "0 0 * * *", expectedDOMRestricted: false, expectedDOWRestricted: false},
+		{name: "Question mark", expr: "0 0 ? * MON", expectedDOMRestricted: false, expectedDOWRestricted: true},
+		{name: "Stepped wildcard", expr: "0 0 */15 * */2", expectedDOMRestricted: false, expectedDOWRestricted: false},
+		{name: "Wildcard in a list", expr: "0 0 *,1 * *", expectedDOMRestricted: false, expectedDOWRestricted: false},
+		{name: "Full range", expr: "0 0 1-31 * 0-6", expectedDOMRestricted: true, expectedDOWRestricted: true},
+		{name: "Stepped value", expr: "0 0 1/15 * 1/2", expectedDOMRestricted: true, expectedDOWRestricted: true},
+		{name: "List starting with a value", expr: "0 0 1,* * *", expectedDOMRestricted: true, expectedDOWRestricted: false},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := Parse(tc.expr)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedDOMRestricted, s.domRestricted)
+			require.Equal(t, tc.expectedDOWRestricted, s.dowRestricted)
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	testcases := []struct {
+		name          string
+		expr          string
+		expectedField string
+	}{
+		{name: "Too few fields", expr: "* * * *", expectedField: "expression"},
+		{name: "Too many fields", expr: "0 * * * * * *", expectedField: "expression"},
+		{name: "Unknown macro", expr: "@fortnightly", expectedField: "expression"},
+		{name: "Second out of range", expr: "60 * * * * *", expectedField: "second"},
+		{name: "Minute out of range", expr: "61 * * * *", expectedField: "minute"},
+		{name: "Hour range out of range", expr: "0 8-25 * * *", expectedField: "hour"},
+		{name: "Zero day of month", expr: "0 0 0 * *", expectedField: "day-of-month"},
+		{name: "Unknown month", expr: "0 0 1 JANX *", expectedField: "month"},
+		{name: "Reversed range", expr: "0 0 * * FRI-MON", expectedField: "day-of-week"},
+		{name: "Zero step", expr: "*/0 * * * *", expectedField: "minute"},
+		{name: "Empty list item", expr: "0,,5 * * * *", expectedField: "minute"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Parse(tc.expr)
+			var parseErr *ParseError
+			require.True(t, errors.As(err, &parseErr), "unexpected error %v", err)
+			require.Equal(t, tc.expectedField, parseErr.Field)
+		})
+	}
+}
+
+func TestIterate(t *testing.T) {
+	testcases := []struct {
+		name     string
+		expr     string
+		start    string
+		end      string
+		max      int
+		expected []string
+	}{
+		{
+			name:     "Weekdays at 9:30",
+			expr:     "30 9 * * MON-FRI",
+			start:    "20210305T000000",
+			end:      "20210310T000000",
+			expected: []string{"20210305T093000", "20210308T093000", "20210309T093000"},
+		},
+		{
+			name:     "Every 20 seconds",
+			expr:     "*/20 * * * * *",
+			start:    "20210301T000010",
+			end:      "20210301T000100",
+			expected: []string{"20210301T000020", "20210301T000040", "20210301T000100"},
+		},
+		{
+			name:     "Monthly macro",
+			expr:     "@monthly",
+			start:    "20210115T000000",
+			end:      "20210501T000000",
+			expected: []string{"20210201T000000", "20210301T000000", "20210401T000000", "20210501T000000"},
+		},
+		{
+			name:     "Sunday as 7",
+			expr:     "0 12 * * 7",
+			start:    "20210301T000000",
+			end:      "20210315T000000",
+			expected: []string{"20210307T120000", "20210314T120000"},
+		},
+		{
+			name:  "Both day fields restricted match either",
+			expr:  "0 0 1,15 * MON",
+			start: "20210301T000000",
+			end:   "20210331T000000",
+			expected: []string{
+				"20210301T000000", "20210308T000000", "20210315T000000", "20210322T000000", "20210329T000000",
+			},
+		},
+		{
+			name:     "Stepped wildcard day of month matches both",
+			expr:     "0 0 */15 * MON",
+			start:    "20210301T000000",
+			end:      "20210531T000000",
+			expected: []string{"20210301T000000", "20210531T000000"},
+		},
+		{
+			name:     "Stops when fn returns false",
+			expr:     "0 * * * *",
+			start:    "20210301T000000",
+			end:      "20210302T000000",
+			max:      2,
+			expected: []string{"20210301T000000", "20210301T010000"},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := Parse(tc.expr)
+			require.NoError(t, err)
+
+			start, err := time.Parse(layout, tc.start)
+			require.NoError(t, err)
+			end, err := time.Parse(layout, tc.end)
+			require.NoError(t, err)
+
+			matches := []string{}
+			s.Iterate(start, end, func(match time.Time) bool {
+				matches = append(matches, match.Format(layout))
+				return tc.max == 0 || len(matches) < tc.max
+			})
+			require.Equal(t, tc.expected, matches)
+		})
+	}
+}
+
+const layout = "20060102T150405"
@@ -2,7 +2,11 @@ package http
 
 import (
 	"encoding/json"
+	"mime"
 	"net/http"
+	"strings"
+
+	"plist/errors"
 )
 
 // Write json to response object.
@@ -12,3 +16,29 @@ func WriteJSON(status int, i interface{}, w http.ResponseWriter) error {
 
 	return json.NewEncoder(w).Encode(i)
 }
+
+// WriteError writes an error with its HTTP status, as an RFC 7807 problem document when the
+// request accepts application/problem+json and in the {status, desc} form otherwise.
+func WriteError(errResp *errors.ErrResp, w http.ResponseWriter, r *http.Request) error {
+	if !Accepts(r, errors.ProblemContentType) {
+		return WriteJSON(errResp.HTTPStatus, errResp, w)
+	}
+
+	w.Header().Set("Content-Type", errors.ProblemContentType)
+	w.WriteHeader(errResp.HTTPStatus)
+
+	return json.NewEncoder(w).Encode(errResp.Problem(r.URL.RequestURI()))
+}
+
+// Accepts reports whether the Accept header of the request lists the given media type.
+func Accepts(r *http.Request, mediaType string) bool {
+	for _, accept := range r.Header.Values("Accept") {
+		for _, mediaRange := range strings.Split(accept, ",") {
+			t, params, err := mime.ParseMediaType(mediaRange)
+			if err == nil && t == mediaType && params["q"] != "0" {
+				return true
+			}
+		}
+	}
+	return false
+}
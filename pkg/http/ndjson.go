@@ -0,0 +1,91 @@
+package http
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// NDJSONContentType is the media type of newline delimited JSON streams.
+const NDJSONContentType = "application/x-ndjson"
+
+// NDJSON stream flushing, whichever comes first.
+const (
+	ndjsonFlushLines    = 256
+	ndjsonFlushInterval = 100 * time.Millisecond
+)
+
+// ndjsonWriteTimeout is how long a stream may go without a flush before the server ends it. Every
+// flush extends the write deadline of the connection, which the server's WriteTimeout otherwise
+// sets once for the whole response.
+const ndjsonWriteTimeout = 60 * time.Second
+
+// NDJSONWriter writes a stream of JSON values, one per line, and flushes them to the client
+// periodically.
+type NDJSONWriter struct {
+	w         http.ResponseWriter
+	buf       *bufio.Writer
+	enc       *json.Encoder
+	status    int
+	started   bool
+	lines     int
+	lastFlush time.Time
+}
+
+// NewNDJSONWriter returns a writer that responds with the given status on its first write.
+func NewNDJSONWriter(status int, w http.ResponseWriter) *NDJSONWriter {
+	buf := bufio.NewWriter(w)
+	return &NDJSONWriter{
+		w:      w,
+		buf:    buf,
+		enc:    json.NewEncoder(buf),
+		status: status,
+	}
+}
+
+// Started reports whether the response has been started, after which its status is fixed.
+func (n *NDJSONWriter) Started() bool {
+	return n.started
+}
+
+// Write writes a value as a single line.
+func (n *NDJSONWriter) Write(i interface{}) error {
+	n.start()
+	if err := n.enc.Encode(i); err != nil {
+		return err
+	}
+
+	n.lines++
+	if n.lines >= ndjsonFlushLines || time.Since(n.lastFlush) >= ndjsonFlushInterval {
+		return n.Flush()
+	}
+	return nil
+}
+
+// Flush sends the buffered lines to the client, it starts an empty response as well.
+func (n *NDJSONWriter) Flush() error {
+	n.start()
+	if err := n.buf.Flush(); err != nil {
+		return err
+	}
+	if flusher, ok := n.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	// Writers without deadlines, e.g. recorders in tests, are never cut off.
+	_ = http.NewResponseController(n.w).SetWriteDeadline(time.Now().Add(ndjsonWriteTimeout))
+	n.lines = 0
+	n.lastFlush = time.Now()
+	return nil
+}
+
+func (n *NDJSONWriter) start() {
+	if n.started {
+		return
+	}
+
+	n.w.Header().Set("Content-Type", NDJSONContentType)
+	n.w.WriteHeader(n.status)
+	n.started = true
+	n.lastFlush = time.Now()
+}
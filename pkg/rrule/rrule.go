@@ -0,0 +1,621 @@
+// Package rrule parses and expands RFC 5545 recurrence rules.
+//
+// Expansion works on wall-clock times represented as UTC time objects, so that the caller
+// decides how a local reading maps to an instant in its timezone.
+package rrule
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency is the FREQ rule part.
+type Frequency int
+
+// Frequencies.
+const (
+	Secondly Frequency = iota
+	Minutely
+	Hourly
+	Daily
+	Weekly
+	Monthly
+	Yearly
+)
+
+var frequencies = map[string]Frequency{
+	"SECONDLY": Secondly,
+	"MINUTELY": Minutely,
+	"HOURLY":   Hourly,
+	"DAILY":    Daily,
+	"WEEKLY":   Weekly,
+	"MONTHLY":  Monthly,
+	"YEARLY":   Yearly,
+}
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// maxEmptyPeriods stops the unbounded expansion of rules that can never match, e.g.
+// BYMONTHDAY=30;BYMONTH=2.
+const maxEmptyPeriods = 1000
+
+// MaxPeriods bounds the number of periods, e.g. seconds of FREQ=SECONDLY, an expansion steps through.
+const MaxPeriods = 1000000
+
+// ErrTooManyPeriods reports an expansion that stepped through MaxPeriods periods without reaching
+// its end.
+var ErrTooManyPeriods = errors.New("recurrence rule expands to too many periods")
+
+// ParseError reports the rule part, or content line, that could not be parsed.
+type ParseError struct {
+	Field string
+	Value string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("invalid %s value %q", e.Field, e.Value)
+}
+
+// Weekday is a BYDAY entry, e.g. -1FR for the last Friday. N is 0 for every such weekday.
+type Weekday struct {
+	N   int
+	Day time.Weekday
+}
+
+// DateTime is an iCalendar DATE-TIME or DATE value. Time holds the wall-clock reading as UTC.
+type DateTime struct {
+	Time time.Time
+	UTC  bool
+	TZID string
+}
+
+// WallClock returns the reading of the value on the wall clock of loc.
+func (d DateTime) WallClock(loc *time.Location) (time.Time, error) {
+	switch {
+	case d.UTC:
+		t := d.Time.In(loc)
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC), nil
+	case d.TZID != "":
+		tzLoc, err := time.LoadLocation(d.TZID)
+		if err != nil {
+			return time.Time{}, err
+		}
+		t := time.Date(d.Time.Year(), d.Time.Month(), d.Time.Day(), d.Time.Hour(), d.Time.Minute(), d.Time.Second(), 0, tzLoc).In(loc)
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC), nil
+	}
+	return d.Time, nil
+}
+
+// Rule is a parsed RRULE.
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	Count      int
+	Until      *DateTime
+	BySecond   []int
+	ByMinute   []int
+	ByHour     []int
+	ByDay      []Weekday
+	ByMonthDay []int
+	ByYearDay  []int
+	ByMonth    []int
+	BySetPos   []int
+	WeekStart  time.Weekday
+}
+
+// Set is a recurrence set: an optional DTSTART, a RRULE and its EXDATEs.
+type Set struct {
+	DTStart *DateTime
+	Rule    *Rule
+	ExDates []DateTime
+}
+
+// Parse parses either a bare rule, e.g. FREQ=MONTHLY;BYDAY=-1FR;BYHOUR=9, or iCalendar content
+// lines with DTSTART, RRULE and EXDATE properties separated by new lines.
+func Parse(content string) (*Set, error) {
+	set := &Set{}
+
+	lines := strings.FieldsFunc(content, func(r rune) bool { return r == '\n' || r == '\r' })
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		name, value, found := strings.Cut(line, ":")
+		if !found {
+			name, value = "RRULE", line
+		}
+		name, params, _ := strings.Cut(name, ";")
+
+		switch strings.ToUpper(name) {
+		case "RRULE":
+			if set.Rule != nil {
+				return nil, &ParseError{Field: "RRULE", Value: value}
+			}
+			rule, err := ParseRule(value)
+			if err != nil {
+				return nil, err
+			}
+			set.Rule = rule
+		case "DTSTART":
+			dt, err := parseDateTime(value, params)
+			if err != nil {
+				return nil, &ParseError{Field: "DTSTART", Value: value}
+			}
+			set.DTStart = &dt
+		case "EXDATE":
+			for _, v := range strings.Split(value, ",") {
+				dt, err := parseDateTime(v, params)
+				if err != nil {
+					return nil, &ParseError{Field: "EXDATE", Value: v}
+				}
+				set.ExDates = append(set.ExDates, dt)
+			}
+		default:
+			return nil, &ParseError{Field: "property", Value: name}
+		}
+	}
+
+	if set.Rule == nil {
+		return nil, &ParseError{Field: "RRULE", Value: content}
+	}
+	return set, nil
+}
+
+// ParseRule parses the value of a RRULE property.
+func ParseRule(value string) (*Rule, error) {
+	rule := &Rule{
+		Freq:      -1,
+		Interval:  1,
+		WeekStart: time.Monday,
+	}
+
+	for _, part := range strings.Split(value, ";") {
+		if part == "" {
+			continue
+		}
+		key, val, found := strings.Cut(part, "=")
+		key = strings.ToUpper(key)
+		if !found || val == "" {
+			return nil, &ParseError{Field: key, Value: val}
+		}
+
+		var err error
+		switch key {
+		case "FREQ":
+			freq, ok := frequencies[strings.ToUpper(val)]
+			if !ok {
+				err = &ParseError{Field: key, Value: val}
+			}
+			rule.Freq = freq
+		case "INTERVAL":
+			rule.Interval, err = parseInt(key, val, 1, 0)
+		case "COUNT":
+			rule.Count, err = parseInt(key, val, 1, 0)
+		case "UNTIL":
+			var until DateTime
+			until, err = parseDateTime(val, "")
+			if err != nil {
+				err = &ParseError{Field: key, Value: val}
+			}
+			rule.Until = &until
+		case "BYSECOND":
+			rule.BySecond, err = parseInts(key, val, 0, 60, false)
+		case "BYMINUTE":
+			rule.ByMinute, err = parseInts(key, val, 0, 59, false)
+		case "BYHOUR":
+			rule.ByHour, err = parseInts(key, val, 0, 23, false)
+		case "BYDAY":
+			rule.ByDay, err = parseWeekdays(key, val)
+		case "BYMONTHDAY":
+			rule.ByMonthDay, err = parseInts(key, val, 1, 31, true)
+		case "BYYEARDAY":
+			rule.ByYearDay, err = parseInts(key, val, 1, 366, true)
+		case "BYMONTH":
+			rule.ByMonth, err = parseInts(key, val, 1, 12, false)
+		case "BYSETPOS":
+			rule.BySetPos, err = parseInts(key, val, 1, 366, true)
+		case "WKST":
+			day, ok := weekdays[strings.ToUpper(val)]
+			if !ok {
+				err = &ParseError{Field: key, Value: val}
+			}
+			rule.WeekStart = day
+		default:
+			err = &ParseError{Field: key, Value: val}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case rule.Freq < 0:
+		return nil, &ParseError{Field: "FREQ", Value: ""}
+	case rule.Count > 0 && rule.Until != nil:
+		return nil, &ParseError{Field: "COUNT", Value: strconv.Itoa(rule.Count)}
+	}
+	return rule, nil
+}
+
+// Iterate calls fn with every occurrence, in order, from start until fn returns false, COUNT
+// occurrences were produced, or the occurrences pass end. Occurrences before start are skipped
+// but still count towards COUNT; without COUNT the expansion begins at the period containing
+// start rather than at dtstart. All times are wall-clock times; a zero end means no bound besides
+// COUNT. It fails with ErrTooManyPeriods once it steps through MaxPeriods periods.
+func (r *Rule) Iterate(dtstart, start, end time.Time, fn func(time.Time) bool) error {
+	first := 0
+	if r.Count == 0 {
+		first = r.period(dtstart, start)
+	}
+
+	count := 0
+	empty := 0
+	for period := first; ; period++ {
+		if period-first == MaxPeriods {
+			return ErrTooManyPeriods
+		}
+		if end.IsZero() && empty == maxEmptyPeriods {
+			return nil
+		}
+		periodStart, occurrences := r.expand(dtstart, period)
+		if !end.IsZero() && periodStart.After(end) {
+			return nil
+		}
+
+		produced := false
+		for _, occurrence := range occurrences {
+			if occurrence.Before(dtstart) {
+				continue
+			}
+			if !end.IsZero() && occurrence.After(end) {
+				return nil
+			}
+			produced = true
+			count++
+			if occurrence.Before(start) {
+				continue
+			}
+			if !fn(occurrence) {
+				return nil
+			}
+			if r.Count > 0 && count >= r.Count {
+				return nil
+			}
+		}
+
+		if produced {
+			empty = 0
+		} else {
+			empty++
+		}
+	}
+}
+
+// period returns the index of the period since dtstart that contains timeObj, or 0 when timeObj
+// precedes dtstart.
+func (r *Rule) period(dtstart, timeObj time.Time) int {
+	var n int64
+	switch r.Freq {
+	case Yearly:
+		n = int64(timeObj.Year() - dtstart.Year())
+	case Monthly:
+		n = int64(timeObj.Year()-dtstart.Year())*12 + int64(timeObj.Month()-dtstart.Month())
+	case Weekly:
+		day := truncateDay(dtstart)
+		weekStart := day.AddDate(0, 0, -int((7+day.Weekday()-r.WeekStart)%7))
+		n = (truncateDay(timeObj).Unix() - weekStart.Unix()) / (7 * 24 * 3600)
+	case Daily:
+		n = (truncateDay(timeObj).Unix() - truncateDay(dtstart).Unix()) / (24 * 3600)
+	default:
+		unit := r.unit()
+		n = (timeObj.Unix() - dtstart.Truncate(unit).Unix()) / int64(unit/time.Second)
+	}
+	if n <= 0 {
+		return 0
+	}
+	return int(n / int64(r.Interval))
+}
+
+// unit returns the length of the periods of HOURLY, MINUTELY and SECONDLY rules.
+func (r *Rule) unit() time.Duration {
+	return map[Frequency]time.Duration{Hourly: time.Hour, Minutely: time.Minute, Secondly: time.Second}[r.Freq]
+}
+
+// expand returns the start of the n-th period since dtstart and its sorted occurrences.
+func (r *Rule) expand(dtstart time.Time, n int) (time.Time, []time.Time) {
+	step := n * r.Interval
+
+	var start time.Time
+	var days []time.Time
+	switch r.Freq {
+	case Yearly:
+		start = time.Date(dtstart.Year()+step, 1, 1, 0, 0, 0, 0, time.UTC)
+		days = r.periodDays(start, start.AddDate(1, 0, 0), dtstart, false)
+	case Monthly:
+		start = time.Date(dtstart.Year(), dtstart.Month()+time.Month(step), 1, 0, 0, 0, 0, time.UTC)
+		days = r.periodDays(start, start.AddDate(0, 1, 0), dtstart, true)
+	case Weekly:
+		day := truncateDay(dtstart)
+		start = day.AddDate(0, 0, -int((7+day.Weekday()-r.WeekStart)%7)+7*step)
+		days = r.weekDays(start, dtstart)
+	case Daily:
+		start = truncateDay(dtstart).AddDate(0, 0, step)
+		if r.matchesDay(start, true) {
+			days = []time.Time{start}
+		}
+	default:
+		// Periods are stepped in seconds, a Duration overflows past 292 years.
+		unit := r.unit()
+		start = time.Unix(dtstart.Truncate(unit).Unix()+int64(step)*int64(unit/time.Second), 0).UTC()
+		switch {
+		case !r.matchesDay(start, true) || !contains(r.ByHour, start.Hour()):
+			return start, nil
+		case r.Freq <= Minutely && !contains(r.ByMinute, start.Minute()):
+			return start, nil
+		case r.Freq == Secondly && !contains(r.BySecond, start.Second()):
+			return start, nil
+		case r.Freq == Secondly:
+			return start, []time.Time{start}
+		}
+		return start, r.applySetPos(r.times(start, dtstart))
+	}
+
+	occurrences := []time.Time{}
+	for _, day := range days {
+		occurrences = append(occurrences, r.times(day, dtstart)...)
+	}
+	return start, r.applySetPos(occurrences)
+}
+
+// periodDays returns the days of a year or month period that match the rule.
+func (r *Rule) periodDays(start, end, dtstart time.Time, monthly bool) []time.Time {
+	if len(r.ByDay) == 0 && len(r.ByMonthDay) == 0 && len(r.ByYearDay) == 0 {
+		// Without day rules the rule repeats the day of DTSTART.
+		months := []int{int(dtstart.Month())}
+		if monthly {
+			months = []int{int(start.Month())}
+		} else if len(r.ByMonth) > 0 {
+			months = r.ByMonth
+		}
+
+		days := []time.Time{}
+		for _, month := range months {
+			day := time.Date(start.Year(), time.Month(month), dtstart.Day(), 0, 0, 0, 0, time.UTC)
+			if day.Day() == dtstart.Day() && contains(r.ByMonth, int(day.Month())) {
+				days = append(days, day)
+			}
+		}
+		return days
+	}
+
+	days := []time.Time{}
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		if r.matchesDay(day, monthly || len(r.ByMonth) > 0) {
+			days = append(days, day)
+		}
+	}
+	return days
+}
+
+// weekDays returns the days of a week period that match the rule.
+func (r *Rule) weekDays(start, dtstart time.Time) []time.Time {
+	days := []time.Time{}
+	for i := 0; i < 7; i++ {
+		day := start.AddDate(0, 0, i)
+		if len(r.ByDay) == 0 && day.Weekday() != dtstart.Weekday() {
+			continue
+		}
+		if r.matchesDay(day, true) {
+			days = append(days, day)
+		}
+	}
+	return days
+}
+
+// matchesDay checks the day against BYMONTH, BYYEARDAY, BYMONTHDAY and BYDAY. Numbered BYDAY
+// entries count weekdays within the month when inMonth is set and within the year otherwise.
+func (r *Rule) matchesDay(day time.Time, inMonth bool) bool {
+	if !contains(r.ByMonth, int(day.Month())) {
+		return false
+	}
+
+	if len(r.ByYearDay) > 0 {
+		daysInYear := time.Date(day.Year(), 12, 31, 0, 0, 0, 0, time.UTC).YearDay()
+		if !containsOrdinal(r.ByYearDay, day.YearDay(), daysInYear) {
+			return false
+		}
+	}
+
+	if len(r.ByMonthDay) > 0 {
+		daysInMonth := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+		if !containsOrdinal(r.ByMonthDay, day.Day(), daysInMonth) {
+			return false
+		}
+	}
+
+	if len(r.ByDay) > 0 {
+		first := time.Date(day.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+		last := time.Date(day.Year(), 12, 31, 0, 0, 0, 0, time.UTC)
+		if inMonth {
+			first = time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+			last = time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC)
+		}
+
+		nth := (day.YearDay()-first.YearDay())/7 + 1
+		nthFromEnd := -((last.YearDay()-day.YearDay())/7 + 1)
+
+		matched := false
+		for _, weekday := range r.ByDay {
+			if weekday.Day == day.Weekday() && (weekday.N == 0 || weekday.N == nth || weekday.N == nthFromEnd) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	return true
+}
+
+// times expands BYHOUR, BYMINUTE and BYSECOND within a day or a sub-daily period.
+func (r *Rule) times(start, dtstart time.Time) []time.Time {
+	hours := []int{start.Hour()}
+	if r.Freq >= Daily {
+		hours = defaultInts(r.ByHour, dtstart.Hour())
+	}
+	minutes := []int{start.Minute()}
+	if r.Freq >= Hourly {
+		minutes = defaultInts(r.ByMinute, dtstart.Minute())
+	}
+	seconds := defaultInts(r.BySecond, dtstart.Second())
+
+	occurrences := []time.Time{}
+	for _, hour := range hours {
+		for _, minute := range minutes {
+			for _, second := range seconds {
+				occurrences = append(occurrences, time.Date(start.Year(), start.Month(), start.Day(), hour, minute, second, 0, time.UTC))
+			}
+		}
+	}
+	sort.Slice(occurrences, func(i, j int) bool { return occurrences[i].Before(occurrences[j]) })
+	return occurrences
+}
+
+// applySetPos keeps the BYSETPOS positions of the sorted occurrences of a period.
+func (r *Rule) applySetPos(occurrences []time.Time) []time.Time {
+	if len(r.BySetPos) == 0 {
+		return occurrences
+	}
+
+	selected := []time.Time{}
+	for i, occurrence := range occurrences {
+		if containsOrdinal(r.BySetPos, i+1, len(occurrences)) {
+			selected = append(selected, occurrence)
+		}
+	}
+	return selected
+}
+
+func parseDateTime(value, params string) (DateTime, error) {
+	dt := DateTime{}
+	for _, param := range strings.Split(params, ";") {
+		if name, val, found := strings.Cut(param, "="); found && strings.EqualFold(name, "TZID") {
+			dt.TZID = val
+		}
+	}
+
+	layout := "20060102T150405"
+	if strings.HasSuffix(value, "Z") {
+		value = strings.TrimSuffix(value, "Z")
+		dt.UTC = true
+	}
+	if len(value) == len("20060102") {
+		layout = "20060102"
+	}
+
+	t, err := time.Parse(layout, value)
+	if err != nil {
+		return DateTime{}, err
+	}
+	dt.Time = t
+	return dt, nil
+}
+
+func parseInt(key, value string, min, max int) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < min || (max > 0 && n > max) {
+		return 0, &ParseError{Field: key, Value: value}
+	}
+	return n, nil
+}
+
+func parseInts(key, value string, min, max int, signed bool) ([]int, error) {
+	values := []int{}
+	for _, v := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(v)
+		abs := n
+		if signed && n < 0 {
+			abs = -n
+		}
+		if err != nil || abs < min || abs > max {
+			return nil, &ParseError{Field: key, Value: v}
+		}
+		values = append(values, n)
+	}
+	return values, nil
+}
+
+func parseWeekdays(key, value string) ([]Weekday, error) {
+	values := []Weekday{}
+	for _, v := range strings.Split(value, ",") {
+		if len(v) < 2 {
+			return nil, &ParseError{Field: key, Value: v}
+		}
+
+		day, ok := weekdays[strings.ToUpper(v[len(v)-2:])]
+		if !ok {
+			return nil, &ParseError{Field: key, Value: v}
+		}
+
+		n := 0
+		if prefix := v[:len(v)-2]; prefix != "" {
+			var err error
+			n, err = strconv.Atoi(prefix)
+			if err != nil || n == 0 || n < -53 || n > 53 {
+				return nil, &ParseError{Field: key, Value: v}
+			}
+		}
+		values = append(values, Weekday{N: n, Day: day})
+	}
+	return values, nil
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// contains reports whether values is empty or contains value.
+func contains(values []int, value int) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// containsOrdinal reports whether values contains the 1-based position, counting negative values from the end.
+func containsOrdinal(values []int, position, total int) bool {
+	for _, v := range values {
+		if v == position || v == position-total-1 {
+			return true
+		}
+	}
+	return false
+}
+
+func defaultInts(values []int, value int) []int {
+	if len(values) == 0 {
+		return []int{value}
+	}
+	return values
+}
@@ -0,0 +1,321 @@
+package rrule
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Examples of RFC 5545 section 3.8.5.3, read as wall-clock times.
+func TestIterateRFC5545Examples(t *testing.T) {
+	testcases := []struct {
+		name     string
+		rule     string
+		dtstart  string
+		end      string
+		max      int
+		expected []string
+	}{
+		{
+			name:    "Daily for 10 occurrences",
+			rule:    "FREQ=DAILY;COUNT=10",
+			dtstart: "19970902T090000",
+			expected: []string{
+				"19970902T090000", "19970903T090000", "19970904T090000", "19970905T090000", "19970906T090000",
+				"19970907T090000", "19970908T090000", "19970909T090000", "19970910T090000", "19970911T090000",
+			},
+		},
+		{
+			name:     "Every other day",
+			rule:     "FREQ=DAILY;INTERVAL=2",
+			dtstart:  "19970902T090000",
+			max:      4,
+			expected: []string{"19970902T090000", "19970904T090000", "19970906T090000", "19970908T090000"},
+		},
+		{
+			name:     "Every 10 days, 5 occurrences",
+			rule:     "FREQ=DAILY;INTERVAL=10;COUNT=5",
+			dtstart:  "19970902T090000",
+			expected: []string{"19970902T090000", "19970912T090000", "19970922T090000", "19971002T090000", "19971012T090000"},
+		},
+		{
+			name:    "Weekly for 10 occurrences",
+			rule:    "FREQ=WEEKLY;COUNT=10",
+			dtstart: "19970902T090000",
+			expected: []string{
+				"19970902T090000", "19970909T090000", "19970916T090000", "19970923T090000", "19970930T090000",
+				"19971007T090000", "19971014T090000", "19971021T090000", "19971028T090000", "19971104T090000",
+			},
+		},
+		{
+			name:    "Weekly on Tuesday and Thursday for five weeks",
+			rule:    "FREQ=WEEKLY;COUNT=10;WKST=SU;BYDAY=TU,TH",
+			dtstart: "19970902T090000",
+			expected: []string{
+				"19970902T090000", "19970904T090000", "19970909T090000", "19970911T090000", "19970916T090000",
+				"19970918T090000", "19970923T090000", "19970925T090000", "19970930T090000", "19971002T090000",
+			},
+		},
+		{
+			name:    "Monthly on the first Friday for 10 occurrences",
+			rule:    "FREQ=MONTHLY;COUNT=10;BYDAY=1FR",
+			dtstart: "19970905T090000",
+			expected: []string{
+				"19970905T090000", "19971003T090000", "19971107T090000", "19971205T090000", "19980102T090000",
+				"19980206T090000", "19980306T090000", "19980403T090000", "19980501T090000", "19980605T090000",
+			},
+		},
+		{
+			name:    "Monthly on the second-to-last Monday for 6 months",
+			rule:    "FREQ=MONTHLY;COUNT=6;BYDAY=-2MO",
+			dtstart: "19970922T090000",
+			expected: []string{
+				"19970922T090000", "19971020T090000", "19971117T090000", "19971222T090000", "19980119T090000",
+				"19980216T090000",
+			},
+		},
+		{
+			name:    "Monthly on the third-to-the-last day of the month",
+			rule:    "FREQ=MONTHLY;BYMONTHDAY=-3",
+			dtstart: "19970928T090000",
+			max:     6,
+			expected: []string{
+				"19970928T090000", "19971029T090000", "19971128T090000", "19971229T090000", "19980129T090000",
+				"19980226T090000",
+			},
+		},
+		{
+			name:     "Third Tuesday, Wednesday or Thursday of the month for 3 months",
+			rule:     "FREQ=MONTHLY;COUNT=3;BYDAY=TU,WE,TH;BYSETPOS=3",
+			dtstart:  "19970904T090000",
+			expected: []string{"19970904T090000", "19971007T090000", "19971106T090000"},
+		},
+		{
+			name:    "Last work day of the month",
+			rule:    "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1",
+			dtstart: "19970929T090000",
+			max:     7,
+			expected: []string{
+				"19970930T090000", "19971031T090000", "19971128T090000", "19971231T090000", "19980130T090000",
+				"19980227T090000", "19980331T090000",
+			},
+		},
+		{
+			name:    "Every Friday the 13th",
+			rule:    "FREQ=MONTHLY;BYDAY=FR;BYMONTHDAY=13",
+			dtstart: "19970902T090000",
+			max:     5,
+			expected: []string{
+				"19980213T090000", "19980313T090000", "19981113T090000", "19990813T090000", "20001013T090000",
+			},
+		},
+		{
+			name:     "Every 3 hours from 9:00 to 17:00 on a specific day",
+			rule:     "FREQ=HOURLY;INTERVAL=3",
+			dtstart:  "19970902T090000",
+			end:      "19970902T170000",
+			expected: []string{"19970902T090000", "19970902T120000", "19970902T150000"},
+		},
+		{
+			name:    "Every 15 minutes for 6 occurrences",
+			rule:    "FREQ=MINUTELY;INTERVAL=15;COUNT=6",
+			dtstart: "19970902T090000",
+			expected: []string{
+				"19970902T090000", "19970902T091500", "19970902T093000", "19970902T094500", "19970902T100000",
+				"19970902T101500",
+			},
+		},
+		{
+			name:    "Yearly in June and July for 10 occurrences",
+			rule:    "FREQ=YEARLY;COUNT=10;BYMONTH=6,7",
+			dtstart: "19970610T090000",
+			expected: []string{
+				"19970610T090000", "19970710T090000", "19980610T090000", "19980710T090000", "19990610T090000",
+				"19990710T090000", "20000610T090000", "20000710T090000", "20010610T090000", "20010710T090000",
+			},
+		},
+		{
+			name:     "Every 20th Monday of the year",
+			rule:     "FREQ=YEARLY;BYDAY=20MO",
+			dtstart:  "19970519T090000",
+			max:      3,
+			expected: []string{"19970519T090000", "19980518T090000", "19990517T090000"},
+		},
+		{
+			name:    "Every Thursday in March",
+			rule:    "FREQ=YEARLY;BYMONTH=3;BYDAY=TH",
+			dtstart: "19970313T090000",
+			max:     7,
+			expected: []string{
+				"19970313T090000", "19970320T090000", "19970327T090000", "19980305T090000", "19980312T090000",
+				"19980319T090000", "19980326T090000",
+			},
+		},
+		{
+			name:     "U.S. Presidential Election day",
+			rule:     "FREQ=YEARLY;INTERVAL=4;BYMONTH=11;BYDAY=TU;BYMONTHDAY=2,3,4,5,6,7,8",
+			dtstart:  "19961105T090000",
+			max:      3,
+			expected: []string{"19961105T090000", "20001107T090000", "20041102T090000"},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			rule, err := ParseRule(tc.rule)
+			require.NoError(t, err)
+
+			dtstart, err := time.Parse(layout, tc.dtstart)
+			require.NoError(t, err)
+			var end time.Time
+			if tc.end != "" {
+				end, err = time.Parse(layout, tc.end)
+				require.NoError(t, err)
+			}
+
+			occurrences := []string{}
+			err = rule.Iterate(dtstart, dtstart, end, func(occurrence time.Time) bool {
+				occurrences = append(occurrences, occurrence.Format(layout))
+				return tc.max == 0 || len(occurrences) < tc.max
+			})
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, occurrences)
+		})
+	}
+}
+
+func TestIterateSparseRule(t *testing.T) {
+	rule, err := ParseRule("FREQ=SECONDLY;BYMINUTE=0;BYSECOND=0")
+	require.NoError(t, err)
+
+	dtstart := time.Date(1997, 9, 2, 9, 0, 1, 0, time.UTC)
+	occurrences := []time.Time{}
+	err = rule.Iterate(dtstart, dtstart, dtstart.Add(3*time.Hour), func(occurrence time.Time) bool {
+		occurrences = append(occurrences, occurrence)
+		return true
+	})
+	require.NoError(t, err)
+	require.Equal(t, []time.Time{
+		time.Date(1997, 9, 2, 10, 0, 0, 0, time.UTC),
+		time.Date(1997, 9, 2, 11, 0, 0, 0, time.UTC),
+		time.Date(1997, 9, 2, 12, 0, 0, 0, time.UTC),
+	}, occurrences)
+}
+
+func TestIterateTooManyPeriods(t *testing.T) {
+	rule, err := ParseRule("FREQ=SECONDLY")
+	require.NoError(t, err)
+
+	dtstart := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	occurrences := 0
+	err = rule.Iterate(dtstart, dtstart, dtstart.AddDate(100, 0, 0), func(time.Time) bool {
+		occurrences++
+		return true
+	})
+	require.ErrorIs(t, err, ErrTooManyPeriods)
+	require.Equal(t, MaxPeriods, occurrences)
+}
+
+func TestIterateFromStart(t *testing.T) {
+	dtstart := time.Date(1997, 9, 2, 9, 0, 0, 0, time.UTC)
+	start := time.Date(1998, 3, 17, 13, 27, 0, 0, time.UTC)
+	end := start.AddDate(0, 3, 0)
+	for _, value := range []string{
+		"FREQ=YEARLY;BYMONTH=3,6;BYDAY=TU",
+		"FREQ=MONTHLY;INTERVAL=2;BYMONTHDAY=17,30",
+		"FREQ=WEEKLY;INTERVAL=3;BYDAY=MO,TU;WKST=SU",
+		"FREQ=DAILY;INTERVAL=5;BYHOUR=9,14",
+		"FREQ=HOURLY;INTERVAL=7",
+		"FREQ=MINUTELY;INTERVAL=13;BYHOUR=14",
+		"FREQ=SECONDLY;INTERVAL=37;BYMINUTE=30;BYHOUR=13",
+	} {
+		t.Run(value, func(t *testing.T) {
+			rule, err := ParseRule(value)
+			require.NoError(t, err)
+
+			// Stepping from DTSTART with a COUNT that is never reached yields the same occurrences.
+			counted := *rule
+			counted.Count = MaxPeriods
+			expected := []time.Time{}
+			err = counted.Iterate(dtstart, start, end, func(occurrence time.Time) bool {
+				expected = append(expected, occurrence)
+				return true
+			})
+			require.NoError(t, err)
+			require.NotEmpty(t, expected)
+
+			occurrences := []time.Time{}
+			err = rule.Iterate(dtstart, start, end, func(occurrence time.Time) bool {
+				occurrences = append(occurrences, occurrence)
+				return true
+			})
+			require.NoError(t, err)
+			require.Equal(t, expected, occurrences)
+		})
+	}
+}
+
+func TestIterateOldDTStart(t *testing.T) {
+	rule, err := ParseRule("FREQ=SECONDLY")
+	require.NoError(t, err)
+
+	dtstart := time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC)
+	start := time.Date(9999, 12, 31, 23, 59, 58, 0, time.UTC)
+	occurrences := []time.Time{}
+	err = rule.Iterate(dtstart, start, time.Time{}, func(occurrence time.Time) bool {
+		occurrences = append(occurrences, occurrence)
+		return len(occurrences) < 2
+	})
+	require.NoError(t, err)
+	require.Equal(t, []time.Time{start, start.Add(time.Second)}, occurrences)
+}
+
+func TestParse(t *testing.T) {
+	set, err := Parse("DTSTART;TZID=America/New_York:19970902T090000\nRRULE:FREQ=DAILY;COUNT=5\nEXDATE:19970903T090000,19970905T090000")
+	require.NoError(t, err)
+	require.Equal(t, &DateTime{Time: time.Date(1997, 9, 2, 9, 0, 0, 0, time.UTC), TZID: "America/New_York"}, set.DTStart)
+	require.Equal(t, Daily, set.Rule.Freq)
+	require.Equal(t, 5, set.Rule.Count)
+	require.Len(t, set.ExDates, 2)
+
+	set, err = Parse("FREQ=MONTHLY;BYDAY=-1FR;BYHOUR=9")
+	require.NoError(t, err)
+	require.Nil(t, set.DTStart)
+	require.Equal(t, []Weekday{{N: -1, Day: time.Friday}}, set.Rule.ByDay)
+	require.Equal(t, []int{9}, set.Rule.ByHour)
+}
+
+func TestParseErrors(t *testing.T) {
+	testcases := []struct {
+		name          string
+		content       string
+		expectedField string
+	}{
+		{name: "Missing FREQ", content: "COUNT=3", expectedField: "FREQ"},
+		{name: "Unknown FREQ", content: "FREQ=FORTNIGHTLY", expectedField: "FREQ"},
+		{name: "Zero INTERVAL", content: "FREQ=DAILY;INTERVAL=0", expectedField: "INTERVAL"},
+		{name: "COUNT and UNTIL", content: "FREQ=DAILY;COUNT=3;UNTIL=19971224T000000Z", expectedField: "COUNT"},
+		{name: "BYHOUR out of range", content: "FREQ=DAILY;BYHOUR=24", expectedField: "BYHOUR"},
+		{name: "BYMONTHDAY zero", content: "FREQ=MONTHLY;BYMONTHDAY=0", expectedField: "BYMONTHDAY"},
+		{name: "Invalid BYDAY", content: "FREQ=WEEKLY;BYDAY=XX", expectedField: "BYDAY"},
+		{name: "Invalid WKST", content: "FREQ=WEEKLY;WKST=XX", expectedField: "WKST"},
+		{name: "Unknown rule part", content: "FREQ=DAILY;FOO=1", expectedField: "FOO"},
+		{name: "Invalid DTSTART", content: "DTSTART:1997\nRRULE:FREQ=DAILY", expectedField: "DTSTART"},
+		{name: "Unknown property", content: "RDATE:19970902T090000\nRRULE:FREQ=DAILY", expectedField: "property"},
+		{name: "Two rules", content: "RRULE:FREQ=DAILY\nRRULE:FREQ=WEEKLY", expectedField: "RRULE"},
+		{name: "Missing rule", content: "DTSTART:19970902T090000", expectedField: "RRULE"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Parse(tc.content)
+			var parseErr *ParseError
+			require.True(t, errors.As(err, &parseErr), "unexpected error %v", err)
+			require.Equal(t, tc.expectedField, parseErr.Field)
+		})
+	}
+}
+
+const layout = "20060102T150405"
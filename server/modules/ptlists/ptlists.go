@@ -1,8 +1,14 @@
 package ptlists
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
 
+	"plist/errors"
 	"plist/internal/app/ptlist"
 
 	pfhttp "plist/pkg/http"
@@ -10,6 +16,9 @@ import (
 	"github.com/gorilla/mux"
 )
 
+// maxBodyBytes is the maximum size of the JSON bodies the module decodes at once.
+const maxBodyBytes = 16 << 20
+
 // Module struct.
 type Module struct {
 	ptlistService *ptlist.Service
@@ -22,6 +31,15 @@ func Setup(router *mux.Router, ptlistService *ptlist.Service) {
 	}
 
 	router.HandleFunc("/ptlist", m.GetPtList).Methods("GET")
+	router.HandleFunc("/ptlist/batch", m.GetBatch).Methods("POST")
+	router.HandleFunc("/ptlist/count", m.GetCount).Methods("GET")
+	router.HandleFunc("/ptlist/next", m.GetNext).Methods("GET")
+	router.HandleFunc("/ptlist/prev", m.GetPrev).Methods("GET")
+	router.HandleFunc("/ptlist/contains", m.GetContains).Methods("GET")
+	router.HandleFunc("/ptlist/bucket", m.GetBucket).Methods("POST")
+	router.HandleFunc("/ptlist/gaps", m.GetGaps).Methods("POST")
+	router.HandleFunc("/ptlist/aggregate", m.GetAggregate).Methods("POST")
+	router.HandleFunc("/ptlist/resample", m.GetResample).Methods("POST")
 }
 
 // GetPtList.
@@ -29,27 +47,352 @@ func (m *Module) GetPtList(w http.ResponseWriter, r *http.Request) {
 
 	ctx := r.Context()
 
+	// Get expected url query values.
+	values := r.URL.Query()
+	req := queryRequest(values)
+	opts := append(queryOptions(values),
+		ptlist.WithLocal(values.Get("local") == "true"),
+		ptlist.WithMode(values.Get("mode")),
+		ptlist.WithLimit(values.Get("limit")),
+		ptlist.WithCursor(values.Get("cursor")),
+	)
+
+	if pfhttp.Accepts(r, pfhttp.NDJSONContentType) {
+		// Call ptlist service, streaming until the client disconnects.
+		streamNDJSON(w, r, func(emit func(interface{}) bool) *errors.ErrResp {
+			return m.ptlistService.Stream(ctx, req, func(entry ptlist.Entry) bool {
+				return emit(entry)
+			}, opts...)
+		})
+		return
+	}
+
+	// Call ptlist service.
+	resp, err := m.ptlistService.List(ctx, req, opts...)
+
+	// Handle error.
+	if err != nil {
+		pfhttp.WriteError(err, w, r)
+		return
+	}
+
+	pfhttp.WriteJSON(http.StatusOK, resp, w)
+}
+
+// GetBatch lists a JSON array of queries.
+func (m *Module) GetBatch(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+
+	// Decode queries.
+	var queries []ptlist.BatchQuery
+	if err := decodeBody(w, r, &queries); err != nil {
+		pfhttp.WriteError(err, w, r)
+		return
+	}
+
+	// Call ptlist service.
+	resp, err := m.ptlistService.GetBatch(ctx, queries)
+
+	// Handle error.
+	if err != nil {
+		pfhttp.WriteError(err, w, r)
+		return
+	}
+
+	pfhttp.WriteJSON(http.StatusOK, resp, w)
+}
+
+// GetCount counts the timestamps of a period list.
+func (m *Module) GetCount(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+
 	// Get expected url query values.
 	values := r.URL.Query()
 	period := values.Get("period")
 	tz := values.Get("tz")
 	t1 := values.Get("t1")
 	t2 := values.Get("t2")
+	opts := queryOptions(values)
 
 	// Call ptlist service.
-	ptlist, err := m.ptlistService.GetPtList(
-		ctx,
-		period,
-		tz,
-		t1,
-		t2,
+	resp, err := m.ptlistService.Count(ctx, period, tz, t1, t2, opts...)
+
+	// Handle error.
+	if err != nil {
+		pfhttp.WriteError(err, w, r)
+		return
+	}
+
+	pfhttp.WriteJSON(http.StatusOK, resp, w)
+}
+
+// GetNext lists the next timestamps of a period.
+func (m *Module) GetNext(w http.ResponseWriter, r *http.Request) {
+	m.getOccurrences(w, r, m.ptlistService.Next)
+}
+
+// GetPrev lists the previous timestamps of a period.
+func (m *Module) GetPrev(w http.ResponseWriter, r *http.Request) {
+	m.getOccurrences(w, r, m.ptlistService.Prev)
+}
+
+// getOccurrences lists n timestamps of a period around a time point with Next or Prev.
+func (m *Module) getOccurrences(w http.ResponseWriter, r *http.Request, list func(ctx context.Context, period, tz, from string, n int, opts ...ptlist.Option) (*ptlist.PtListResponse, *errors.ErrResp)) {
+
+	ctx := r.Context()
+
+	// Get expected url query values.
+	values := r.URL.Query()
+	period := values.Get("period")
+	tz := values.Get("tz")
+	from := values.Get("from")
+	n := 1
+	if value := values.Get("n"); value != "" {
+		var err error
+		n, err = strconv.Atoi(value)
+		if err != nil {
+			errResp := errors.Wrap(errors.InvalidNumber, err)
+			errResp.Param = "n"
+			pfhttp.WriteError(errResp, w, r)
+			return
+		}
+	}
+	opts := append(queryOptions(values),
+		ptlist.WithLocal(values.Get("local") == "true"),
+		ptlist.WithMode(values.Get("mode")),
 	)
 
+	// Call ptlist service.
+	resp, err := list(ctx, period, tz, from, n, opts...)
+
 	// Handle error.
 	if err != nil {
-		pfhttp.WriteJSON(http.StatusInternalServerError, err, w)
+		pfhttp.WriteError(err, w, r)
 		return
 	}
 
-	pfhttp.WriteJSON(http.StatusOK, ptlist, w)
+	pfhttp.WriteJSON(http.StatusOK, resp, w)
+}
+
+// GetContains tells whether a time point is a timestamp of a period list.
+func (m *Module) GetContains(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+
+	// Get expected url query values.
+	values := r.URL.Query()
+	period := values.Get("period")
+	tz := values.Get("tz")
+	ts := values.Get("ts")
+	opts := queryOptions(values)
+
+	// Call ptlist service.
+	resp, err := m.ptlistService.Contains(ctx, period, tz, ts, opts...)
+
+	// Handle error.
+	if err != nil {
+		pfhttp.WriteError(err, w, r)
+		return
+	}
+
+	pfhttp.WriteJSON(http.StatusOK, resp, w)
+}
+
+// GetBucket maps the timestamps of a JSON body to the periods they fall in.
+func (m *Module) GetBucket(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+
+	// Decode request.
+	var req ptlist.BucketRequest
+	if err := decodeBody(w, r, &req); err != nil {
+		pfhttp.WriteError(err, w, r)
+		return
+	}
+
+	// Call ptlist service.
+	resp, err := m.ptlistService.Bucket(ctx, req.Period, req.TZ, req.Timestamps, req.Options()...)
+
+	// Handle error.
+	if err != nil {
+		pfhttp.WriteError(err, w, r)
+		return
+	}
+
+	pfhttp.WriteJSON(http.StatusOK, resp, w)
+}
+
+// GetAggregate rolls the points of a JSON body up into the periods of a range.
+func (m *Module) GetAggregate(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+
+	// Decode request.
+	var req ptlist.AggregateRequest
+	if err := decodeBody(w, r, &req); err != nil {
+		pfhttp.WriteError(err, w, r)
+		return
+	}
+
+	// Call ptlist service.
+	resp, err := m.ptlistService.Aggregate(ctx, req.Period, req.TZ, req.T1, req.T2, req.Points, req.Options()...)
+
+	// Handle error.
+	if err != nil {
+		pfhttp.WriteError(err, w, r)
+		return
+	}
+
+	pfhttp.WriteJSON(http.StatusOK, resp, w)
+}
+
+// GetResample resamples the points of a JSON body onto the timestamps of a range.
+func (m *Module) GetResample(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+
+	// Decode request.
+	var req ptlist.ResampleRequest
+	if err := decodeBody(w, r, &req); err != nil {
+		pfhttp.WriteError(err, w, r)
+		return
+	}
+
+	// Call ptlist service.
+	resp, err := m.ptlistService.Resample(ctx, req.Period, req.TZ, req.T1, req.T2, req.Points, req.Options()...)
+
+	// Handle error.
+	if err != nil {
+		pfhttp.WriteError(err, w, r)
+		return
+	}
+
+	pfhttp.WriteJSON(http.StatusOK, resp, w)
+}
+
+// GetGaps compares the JSON array of observed timestamps in the body with the schedule of the
+// query, reading the body as it goes.
+func (m *Module) GetGaps(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+
+	// Get expected url query values.
+	values := r.URL.Query()
+	req := queryRequest(values)
+	opts := queryOptions(values)
+	next := readArray(json.NewDecoder(r.Body))
+
+	if pfhttp.Accepts(r, pfhttp.NDJSONContentType) {
+		// Call ptlist service, streaming the gaps as they are found.
+		streamNDJSON(w, r, func(emit func(interface{}) bool) *errors.ErrResp {
+			return m.ptlistService.Gaps(ctx, req, next, func(gap ptlist.Gap) bool {
+				return emit(gap)
+			}, opts...)
+		})
+		return
+	}
+
+	// Call ptlist service.
+	resp, err := m.ptlistService.GetGaps(ctx, req, next, opts...)
+
+	// Handle error.
+	if err != nil {
+		pfhttp.WriteError(err, w, r)
+		return
+	}
+
+	pfhttp.WriteJSON(http.StatusOK, resp, w)
+}
+
+// queryRequest returns the schedule, timezone and time points of the url query values.
+func queryRequest(values url.Values) ptlist.PtListRequest {
+	return ptlist.PtListRequest{
+		Period: values.Get("period"),
+		RRule:  values.Get("rrule"),
+		Cron:   values.Get("cron"),
+		TZ:     values.Get("tz"),
+		T1:     values.Get("t1"),
+		T2:     values.Get("t2"),
+	}
+}
+
+// queryOptions returns the calendar, DST policy, input and output format options of the url query
+// values, which every query endpoint accepts.
+func queryOptions(values url.Values) []ptlist.Option {
+	return []ptlist.Option{
+		ptlist.WithWeekStart(values.Get("week_start")),
+		ptlist.WithYearStart(values.Get("year_start")),
+		ptlist.WithDST(values.Get("dst")),
+		ptlist.WithInputFormat(values.Get("input_format")),
+		ptlist.WithOutputFormat(values.Get("output_format")),
+	}
+}
+
+// streamNDJSON responds with the values run emits as newline delimited JSON, emit reports whether
+// the client still reads them. An error of run is written as the response while nothing has been
+// streamed and in a last line otherwise.
+func streamNDJSON(w http.ResponseWriter, r *http.Request, run func(emit func(interface{}) bool) *errors.ErrResp) {
+	stream := pfhttp.NewNDJSONWriter(http.StatusOK, w)
+
+	err := run(func(v interface{}) bool {
+		return stream.Write(v) == nil
+	})
+
+	// Handle error, in a last line once the stream has started.
+	if err != nil {
+		if !stream.Started() {
+			pfhttp.WriteError(err, w, r)
+			return
+		}
+		stream.Write(struct {
+			Error *errors.ErrResp `json:"error"`
+		}{err})
+	}
+
+	stream.Flush()
+}
+
+// decodeBody decodes a JSON request body of at most maxBodyBytes.
+func decodeBody(w http.ResponseWriter, r *http.Request, v interface{}) *errors.ErrResp {
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes)).Decode(v); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return errors.Wrap(errors.BodyTooLarge, err)
+		}
+		return errors.Wrap(errors.InvalidRequestBody, err)
+	}
+	return nil
+}
+
+// readArray returns a reader of a JSON array of strings that decodes one element at a time.
+func readArray(dec *json.Decoder) ptlist.TimestampReader {
+	var started, ended bool
+	return func() (string, bool, error) {
+		if ended {
+			return "", false, nil
+		}
+		if !started {
+			started = true
+			token, err := dec.Token()
+			if err != nil {
+				return "", false, err
+			}
+			if token != json.Delim('[') {
+				return "", false, fmt.Errorf("expected an array of timestamps, got %v", token)
+			}
+		}
+		if !dec.More() {
+			ended = true
+			_, err := dec.Token()
+			return "", false, err
+		}
+
+		var value string
+		if err := dec.Decode(&value); err != nil {
+			return "", false, err
+		}
+		return value, true, nil
+	}
 }
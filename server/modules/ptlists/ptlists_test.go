@@ -0,0 +1,381 @@
+package ptlists
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"plist/errors"
+	"plist/internal/app/ptlist"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPtListStatusCodes(t *testing.T) {
+	testcases := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedCode   int
+		expectedParam  string
+	}{
+		{
+			name:           "Success test",
+			query:          "period=1h&tz=Europe/Athens&t1=20210714T204603Z&t2=20210715T123456Z",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Unsupported period test",
+			query:          "period=15s&tz=Europe/Athens&t1=20210714T204603Z&t2=20210715T123456Z",
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   errors.UnsupportedPeriod,
+			expectedParam:  "period",
+		},
+		{
+			name:           "Unknown timezone test",
+			query:          "period=1h&tz=Europe/Aten&t1=20210714T204603Z&t2=20210715T123456Z",
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   errors.TimezoneLoadingError,
+			expectedParam:  "tz",
+		},
+		{
+			name:           "Unparsable t2 test",
+			query:          "period=1h&tz=Europe/Athens&t1=20210714T204603Z&t2=tomorrow-1x",
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   errors.TimeParsingError,
+			expectedParam:  "t2",
+		},
+		{
+			name:           "Unsupported week start test",
+			query:          "period=1w&tz=Europe/Athens&t1=20210714T204603Z&t2=20210815T123456Z&week_start=someday",
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   errors.UnsupportedWeekStart,
+			expectedParam:  "week_start",
+		},
+		{
+			name:           "Multiple errors test",
+			query:          "period=15s&tz=Europe/Aten&t1=20210714T204603Z&t2=tomorrow-1x",
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   errors.ValidationError,
+		},
+		{
+			name:           "Ordering test",
+			query:          "period=1h&tz=Europe/Athens&t1=20210715T123456Z&t2=20210714T204603Z",
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   errors.InvalidRange,
+			expectedParam:  "t2",
+		},
+		{
+			name:           "Invalid cron test",
+			query:          "cron=61+*+*+*+*&tz=Europe/Athens&t1=20210714T204603Z&t2=20210715T123456Z",
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   errors.CronParsingError,
+			expectedParam:  "cron",
+		},
+	}
+
+	router := mux.NewRouter()
+	Setup(router, ptlist.NewService())
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+
+			req := httptest.NewRequest(http.MethodGet, "/ptlist?"+tc.query, nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			require.Equal(t, tc.expectedStatus, rec.Code)
+			if tc.expectedStatus == http.StatusOK {
+				return
+			}
+
+			var errResp errors.ErrResp
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&errResp))
+			require.Equal(t, "error", errResp.Status)
+			require.Equal(t, tc.expectedCode, errResp.Code)
+			require.Equal(t, tc.expectedParam, errResp.Param)
+		})
+	}
+}
+
+func TestGetPtListProblemDocument(t *testing.T) {
+	router := mux.NewRouter()
+	Setup(router, ptlist.NewService())
+
+	t.Run("Problem test", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/ptlist?period=1h&tz=Europe/Aten&t1=20210714T204603Z&t2=20210715T123456Z", nil)
+		req.Header.Set("Accept", "application/problem+json, application/json;q=0.5")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		require.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+
+		var problem errors.Problem
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&problem))
+		require.Equal(t, errors.Problem{
+			Type:     "urn:plist:error:102",
+			Title:    "Could not load given timezone location",
+			Status:   http.StatusUnprocessableEntity,
+			Detail:   "Could not load given timezone location: unknown time zone Europe/Aten",
+			Instance: "/ptlist?period=1h&tz=Europe/Aten&t1=20210714T204603Z&t2=20210715T123456Z",
+			Code:     errors.TimezoneLoadingError,
+			Param:    "tz",
+		}, problem)
+	})
+
+	t.Run("Plain JSON test", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/ptlist?period=1h&tz=Europe/Aten&t1=20210714T204603Z&t2=20210715T123456Z", nil)
+		req.Header.Set("Accept", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		require.JSONEq(t, `{"status":"error","desc":"Could not load given timezone location: unknown time zone Europe/Aten","code":102,"param":"tz"}`, rec.Body.String())
+	})
+}
+
+func TestGetBatch(t *testing.T) {
+	router := mux.NewRouter()
+	Setup(router, ptlist.NewService())
+
+	t.Run("Batch test", func(t *testing.T) {
+		body := `[
+			{"period": "1h", "tz": "Europe/Athens", "t1": "20210714T204603Z", "t2": "20210714T223000Z"},
+			{"period": "15s", "tz": "Europe/Athens", "t1": "20210714T204603Z", "t2": "20210714T223000Z"}
+		]`
+		req := httptest.NewRequest(http.MethodPost, "/ptlist/batch", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.JSONEq(t, `[
+			{"result": {"timestamps": ["20210714T210000Z", "20210714T220000Z"]}},
+			{"error": {"status": "error", "desc": "Unsupported period", "code": 100, "param": "period"}}
+		]`, rec.Body.String())
+	})
+
+	t.Run("Invalid body test", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/ptlist/batch", strings.NewReader(`{"period": "1h"}`))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Body too large test", func(t *testing.T) {
+		body := `[` + strings.Repeat(`{"period": "1h", "tz": "Europe/Athens"},`, maxBodyBytes/32) + `{}]`
+		req := httptest.NewRequest(http.MethodPost, "/ptlist/batch", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	})
+}
+
+func TestGetPtListStream(t *testing.T) {
+	router := mux.NewRouter()
+	Setup(router, ptlist.NewService())
+
+	t.Run("Stream test", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/ptlist?period=1d&tz=Europe/Athens&t1=20211029T210000Z&t2=20211031T000000Z&mode=intervals", nil)
+		req.Header.Set("Accept", "application/x-ndjson")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "application/x-ndjson", rec.Header().Get("Content-Type"))
+		require.Equal(t, `{"start":"20211029T210000Z","end":"20211030T210000Z","label":"2021-10-30"}
+{"start":"20211030T210000Z","end":"20211031T220000Z","label":"2021-10-31"}
+`, rec.Body.String())
+	})
+
+	t.Run("Invalid stream test", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/ptlist?period=15s&tz=Europe/Athens&t1=20211029T210000Z&t2=20211031T000000Z", nil)
+		req.Header.Set("Accept", "application/x-ndjson")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	})
+
+	t.Run("Write timeout test", func(t *testing.T) {
+		server := httptest.NewUnstartedServer(router)
+		server.Config.WriteTimeout = 200 * time.Millisecond
+		server.Start()
+		defer server.Close()
+
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/ptlist?cron=*+*+*+*+*+*&tz=UTC&t1=20210101T000000Z&t2=20220101T000000Z", nil)
+		require.NoError(t, err)
+		req.Header.Set("Accept", "application/x-ndjson")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		// Read well past the write timeout of the server, the stream goes on.
+		scanner := bufio.NewScanner(resp.Body)
+		begin := time.Now()
+		for time.Since(begin) < time.Second {
+			require.True(t, scanner.Scan(), "stream ended: %v", scanner.Err())
+		}
+		require.NotContains(t, scanner.Text(), "error")
+	})
+}
+
+func TestGetCount(t *testing.T) {
+	router := mux.NewRouter()
+	Setup(router, ptlist.NewService())
+
+	req := httptest.NewRequest(http.MethodGet, "/ptlist/count?period=1h&tz=Europe/Athens&t1=20210101T000000Z&t2=20220101T000000Z", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"count": 8761}`, rec.Body.String())
+}
+
+func TestGetNextAndPrev(t *testing.T) {
+	testcases := []struct {
+		name               string
+		target             string
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name:               "Next test",
+			target:             "/ptlist/next?period=1h&tz=Europe/Athens&from=20211031T003000Z&n=2",
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"timestamps": ["20211031T010000Z", "20211031T020000Z"]}`,
+		},
+		{
+			name:               "Prev test",
+			target:             "/ptlist/prev?period=1d&tz=Europe/Athens&from=20211031T003000Z",
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"timestamps": ["20211030T210000Z"]}`,
+		},
+		{
+			name:               "Invalid number test",
+			target:             "/ptlist/next?period=1h&tz=Europe/Athens&from=20211031T003000Z&n=two",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `{"status": "error", "desc": "Invalid number of occurrences: strconv.Atoi: parsing \"two\": invalid syntax", "code": 122, "param": "n"}`,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := mux.NewRouter()
+			Setup(router, ptlist.NewService())
+
+			req := httptest.NewRequest(http.MethodGet, tc.target, nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			require.Equal(t, tc.expectedStatusCode, rec.Code)
+			require.JSONEq(t, tc.expectedBody, rec.Body.String())
+		})
+	}
+}
+
+func TestGetContains(t *testing.T) {
+	router := mux.NewRouter()
+	Setup(router, ptlist.NewService())
+
+	req := httptest.NewRequest(http.MethodGet, "/ptlist/contains?period=1h&tz=Asia/Kolkata&ts=20210714T203000Z", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"contains": true, "prev": "20210714T193000Z", "next": "20210714T213000Z"}`, rec.Body.String())
+}
+
+func TestGetBucket(t *testing.T) {
+	router := mux.NewRouter()
+	Setup(router, ptlist.NewService())
+
+	body := `{"period": "1mo", "tz": "Europe/Athens", "timestamps": ["2021-10-31T23:30:00Z"], "output_format": "rfc3339local"}`
+	req := httptest.NewRequest(http.MethodPost, "/ptlist/bucket", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"buckets": [{"timestamp": "2021-11-01T01:30:00+02:00", "start": "2021-11-01T00:00:00+02:00", "end": "2021-12-01T00:00:00+02:00", "label": "2021-11"}]}`, rec.Body.String())
+}
+
+func TestGetGaps(t *testing.T) {
+	router := mux.NewRouter()
+	Setup(router, ptlist.NewService())
+
+	target := "/ptlist/gaps?period=1h&tz=Europe/Athens&t1=20210714T000000Z&t2=20210714T030000Z"
+	body := `["20210714T000000Z", "20210714T010000Z", "20210714T010000Z", "20210714T023000Z"]`
+
+	t.Run("Gaps test", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.JSONEq(t, `{"missing": ["20210714T020000Z", "20210714T030000Z"], "off_schedule": ["20210714T023000Z"], "duplicates": ["20210714T010000Z"]}`, rec.Body.String())
+	})
+
+	t.Run("Stream test", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		req.Header.Set("Accept", "application/x-ndjson")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, `{"kind":"duplicate","timestamp":"20210714T010000Z"}
+{"kind":"missing","timestamp":"20210714T020000Z"}
+{"kind":"off_schedule","timestamp":"20210714T023000Z"}
+{"kind":"missing","timestamp":"20210714T030000Z"}
+`, rec.Body.String())
+	})
+
+	t.Run("Invalid body test", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(`{"timestamps": []}`))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Contains(t, rec.Body.String(), `"code":117`)
+	})
+}
+
+func TestGetAggregate(t *testing.T) {
+	router := mux.NewRouter()
+	Setup(router, ptlist.NewService())
+
+	body := `{"period": "1h", "tz": "UTC", "t1": "20210714T000000Z", "t2": "20210714T010000Z", "points": [{"timestamp": "20210714T001500Z", "value": 1.5}, {"timestamp": "20210714T004500Z", "value": 2.5}], "fill": "zero"}`
+	req := httptest.NewRequest(http.MethodPost, "/ptlist/aggregate", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"periods": [
+		{"start": "20210714T000000Z", "end": "20210714T010000Z", "label": "2021-07-14T00:00+00:00", "count": 2, "sum": 4, "avg": 2, "min": 1.5, "max": 2.5, "first": 1.5, "last": 2.5},
+		{"start": "20210714T010000Z", "end": "20210714T020000Z", "label": "2021-07-14T01:00+00:00", "count": 0, "sum": 0, "avg": 0, "min": 0, "max": 0, "first": 0, "last": 0}
+	]}`, rec.Body.String())
+}
+
+func TestGetResample(t *testing.T) {
+	router := mux.NewRouter()
+	Setup(router, ptlist.NewService())
+
+	body := `{"period": "15m", "tz": "UTC", "t1": "20210714T000000Z", "t2": "20210714T003000Z", "points": [{"timestamp": "20210714T000000Z", "value": 0}, {"timestamp": "20210714T002000Z", "value": 20}], "method": "linear", "output_format": "rfc3339"}`
+	req := httptest.NewRequest(http.MethodPost, "/ptlist/resample", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"samples": [
+		{"timestamp": "2021-07-14T00:00:00Z", "value": 0},
+		{"timestamp": "2021-07-14T00:15:00Z", "value": 15},
+		{"timestamp": "2021-07-14T00:30:00Z", "value": null}
+	]}`, rec.Body.String())
+}
@@ -5,7 +5,6 @@ import (
 	"log"
 	"net/http"
 	"plist/server/modules/ptlists"
-	"plist/utils"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -45,7 +44,7 @@ func (server *ApplicationServer) Run(port string) error {
 	}
 	log.Println(server.httpServer)
 
-	if err := server.httpServer.ListenAndServe(); utils.CheckErr(err) && err != http.ErrServerClosed {
+	if err := server.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		return err
 	}
 
@@ -0,0 +1,135 @@
+package utils
+
+import (
+	"plist/errors"
+	"time"
+)
+
+// DSTPolicy decides how wall-clock times inside a daylight saving gap (spring forward) or
+// overlap (fall back) map to instants.
+type DSTPolicy string
+
+// DST policies.
+//
+//	policy         gap                             overlap
+//	skip           dropped                         earliest instant
+//	shift-forward  moved forward by the gap        earliest instant
+//	earliest       moved back by the gap           earliest instant
+//	latest         moved forward by the gap        latest instant
+//	both           moved forward by the gap        both instants
+const (
+	DSTSkip         DSTPolicy = "skip"
+	DSTShiftForward DSTPolicy = "shift-forward"
+	DSTEarliest     DSTPolicy = "earliest"
+	DSTLatest       DSTPolicy = "latest"
+	DSTBoth         DSTPolicy = "both"
+)
+
+// DefaultDSTPolicy keeps sub-daily periods contiguous: a 25 hour day lists 25 hours.
+const DefaultDSTPolicy = DSTBoth
+
+// ParseDSTPolicy parses a DST policy name, the default policy is used when empty.
+func ParseDSTPolicy(policy string) (DSTPolicy, *errors.ErrResp) {
+	switch p := DSTPolicy(policy); p {
+	case "":
+		return DefaultDSTPolicy, nil
+	case DSTSkip, DSTShiftForward, DSTEarliest, DSTLatest, DSTBoth:
+		return p, nil
+	}
+
+	return "", errors.GetError(errors.UnsupportedDSTPolicy)
+}
+
+// Resolve returns the instants, in order, that a wall-clock reading maps to in the given
+// location according to the DST policy. It returns no instants for skipped gap times.
+func Resolve(wallClock time.Time, loc *time.Location, policy DSTPolicy) []time.Time {
+	// Offsets a day before and after enclose any single transition.
+	_, offsetBefore := wallClock.Add(-24 * time.Hour).In(loc).Zone()
+	_, offsetAfter := wallClock.Add(24 * time.Hour).In(loc).Zone()
+
+	before := wallClock.Add(-time.Duration(offsetBefore) * time.Second).In(loc)
+	after := wallClock.Add(-time.Duration(offsetAfter) * time.Second).In(loc)
+	_, beforeOffset := before.Zone()
+	_, afterOffset := after.Zone()
+	beforeValid := beforeOffset == offsetBefore
+	afterValid := afterOffset == offsetAfter
+
+	switch {
+	case offsetBefore == offsetAfter || (beforeValid && !afterValid):
+		return []time.Time{before}
+	case afterValid && !beforeValid:
+		return []time.Time{after}
+	case beforeValid && afterValid:
+		// Overlap: the reading happens once before and once after the transition.
+		earliest, latest := before, after
+		if latest.Before(earliest) {
+			earliest, latest = latest, earliest
+		}
+		switch policy {
+		case DSTLatest:
+			return []time.Time{latest}
+		case DSTBoth:
+			return []time.Time{earliest, latest}
+		}
+		return []time.Time{earliest}
+	}
+
+	// Gap: reading the time with the offset before the transition lands after the gap.
+	switch policy {
+	case DSTSkip:
+		return nil
+	case DSTEarliest:
+		return []time.Time{after}
+	}
+	return []time.Time{before}
+}
+
+// FirstWallClock returns the earliest wall-clock reading that may map to an instant at or after
+// the given one. After a fall back transition wall-clock readings repeat, so this is the reading
+// with the offset a day later when that offset is lower.
+func FirstWallClock(timeObj time.Time, loc *time.Location) time.Time {
+	wallClock := WallClock(timeObj.In(loc))
+	_, offsetAfter := timeObj.Add(24 * time.Hour).In(loc).Zone()
+	if earlier := timeObj.UTC().Add(time.Duration(offsetAfter) * time.Second); earlier.Before(wallClock) {
+		return earlier
+	}
+	return wallClock
+}
+
+// LastWallClock returns the latest wall-clock reading that may map to an instant at or before
+// the given one, the reading with the offset a day earlier when that offset is higher.
+func LastWallClock(timeObj time.Time, loc *time.Location) time.Time {
+	wallClock := WallClock(timeObj.In(loc))
+	_, offsetBefore := timeObj.Add(-24 * time.Hour).In(loc).Zone()
+	if later := timeObj.UTC().Add(time.Duration(offsetBefore) * time.Second); later.After(wallClock) {
+		return later
+	}
+	return wallClock
+}
+
+// PeriodEnd returns the first instant after the given one at which the wall clock of loc reaches
+// the end of the period holding the reading of that instant. A boundary inside a daylight saving
+// gap is reached when the gap ends.
+func PeriodEnd(timeObj time.Time, loc *time.Location, period Period) (time.Time, *errors.ErrResp) {
+	boundary, ok := truncate(WallClock(timeObj.In(loc)), period)
+	if !ok {
+		return time.Time{}, errors.GetError(errors.TimeRoundingError)
+	}
+	if errResp := AddPeriod(&boundary, period); errResp != nil {
+		return time.Time{}, errResp
+	}
+
+	instants := Resolve(boundary, loc, DSTBoth)
+	end := instants[len(instants)-1]
+	for _, instant := range instants {
+		if instant.After(timeObj) {
+			end = instant
+			break
+		}
+	}
+	if !WallClock(end.In(loc)).Equal(boundary) {
+		// Gap: the resolved instant is moved forward past the transition.
+		end, _ = end.In(loc).ZoneBounds()
+	}
+	return end.UTC(), nil
+}
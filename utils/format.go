@@ -0,0 +1,146 @@
+package utils
+
+import (
+	"plist/errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Input formats of time points.
+const (
+	InputCompact   = "compact"
+	InputRFC3339   = "rfc3339"
+	InputISO8601   = "iso8601"
+	InputDate      = "date"
+	InputUnix      = "unix"
+	InputUnixMilli = "unixms"
+)
+
+// Output formats of time points.
+const (
+	OutputCompact      = "compact"
+	OutputRFC3339      = "rfc3339"
+	OutputRFC3339Local = "rfc3339local"
+	OutputUnix         = "unix"
+	OutputUnixMilli    = "unixms"
+)
+
+// CompactLayout is the original 20060102T150405Z time point layout.
+const CompactLayout = "20060102T150405Z"
+
+// iso8601Layouts are ISO 8601 extended layouts without an offset, read on the local wall clock.
+var iso8601Layouts = []string{
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04",
+	"20060102T150405",
+}
+
+// dateLayouts are date-only layouts, read as local midnight.
+var dateLayouts = []string{
+	"2006-01-02",
+	"20060102",
+}
+
+// autoFormats is the order in which input formats are detected. Digit-only values of 8 digits
+// are dates, other ones epoch seconds and from 13 digits on epoch milliseconds.
+var autoFormats = []string{InputCompact, InputRFC3339, InputISO8601, InputDate, InputUnix}
+
+// ParseInputFormat validates an input format name, an empty name detects the format per value.
+func ParseInputFormat(format string) (string, *errors.ErrResp) {
+	switch format {
+	case "", InputCompact, InputRFC3339, InputISO8601, InputDate, InputUnix, InputUnixMilli:
+		return format, nil
+	}
+
+	return "", errors.GetError(errors.UnsupportedInputFormat)
+}
+
+// ParseTime parses a time point in the given input format, or detects the format when empty.
+// Values without an offset are read on the wall clock of the given location.
+func ParseTime(value, format string, loc *time.Location) (time.Time, *errors.ErrResp) {
+	if format != "" {
+		if timeObj, ok := parseTime(value, format, loc); ok {
+			return timeObj, nil
+		}
+		return time.Time{}, errors.GetError(errors.TimeParsingError)
+	}
+
+	for _, format := range autoFormats {
+		if format == InputUnix && len(strings.TrimPrefix(value, "-")) >= 13 {
+			format = InputUnixMilli
+		}
+		if timeObj, ok := parseTime(value, format, loc); ok {
+			return timeObj, nil
+		}
+	}
+	return time.Time{}, errors.GetError(errors.TimeParsingError)
+}
+
+func parseTime(value, format string, loc *time.Location) (time.Time, bool) {
+	switch format {
+	case InputCompact:
+		timeObj, err := time.Parse(CompactLayout, value)
+		return timeObj, err == nil
+	case InputRFC3339:
+		timeObj, err := time.Parse(time.RFC3339Nano, value)
+		return timeObj.UTC(), err == nil
+	case InputISO8601:
+		if timeObj, err := time.Parse(time.RFC3339Nano, value); err == nil {
+			return timeObj.UTC(), true
+		}
+		return parseWallClock(value, iso8601Layouts, loc)
+	case InputDate:
+		return parseWallClock(value, dateLayouts, loc)
+	case InputUnix, InputUnixMilli:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return time.Time{}, false
+		}
+		if format == InputUnixMilli {
+			return time.UnixMilli(n).UTC(), true
+		}
+		return time.Unix(n, 0).UTC(), true
+	}
+
+	return time.Time{}, false
+}
+
+// parseWallClock parses a local wall-clock reading with the first matching layout.
+func parseWallClock(value string, layouts []string, loc *time.Location) (time.Time, bool) {
+	for _, layout := range layouts {
+		if wallClock, err := time.Parse(layout, value); err == nil {
+			return Resolve(wallClock, loc, DSTShiftForward)[0].UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// ParseOutputFormat validates an output format name, the compact format is used when empty.
+func ParseOutputFormat(format string) (string, *errors.ErrResp) {
+	switch format {
+	case "":
+		return OutputCompact, nil
+	case OutputCompact, OutputRFC3339, OutputRFC3339Local, OutputUnix, OutputUnixMilli:
+		return format, nil
+	}
+
+	return "", errors.GetError(errors.UnsupportedOutputFormat)
+}
+
+// FormatTime formats a time point in the given output format. Only the rfc3339local format
+// uses the given location, all others are in UTC.
+func FormatTime(timeObj time.Time, format string, loc *time.Location) string {
+	switch format {
+	case OutputRFC3339:
+		return timeObj.UTC().Format(time.RFC3339)
+	case OutputRFC3339Local:
+		return timeObj.In(loc).Format(time.RFC3339)
+	case OutputUnix:
+		return strconv.FormatInt(timeObj.Unix(), 10)
+	case OutputUnixMilli:
+		return strconv.FormatInt(timeObj.UnixMilli(), 10)
+	}
+
+	return timeObj.UTC().Format(CompactLayout)
+}
@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"plist/errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Period units.
+const (
+	Minute  = "m"
+	Hour    = "h"
+	Day     = "d"
+	Week    = "w"
+	Month   = "mo"
+	Quarter = "q"
+	Year    = "y"
+)
+
+// periodRegexp matches periods such as 15m, 2h, 3d, 1w, 2mo, 1q and 5y.
+var periodRegexp = regexp.MustCompile(`^([0-9]+)(m|h|d|w|mo|q|y)$`)
+
+// Calendar holds the anchors of week, month, quarter and year periods.
+type Calendar struct {
+	// WeekStart is the first day of week periods, Monday by default as in ISO 8601.
+	WeekStart time.Weekday
+
+	// YearStart is the first month of month, quarter and year periods, January by default.
+	YearStart time.Month
+}
+
+// DefaultCalendar starts weeks on Monday and years in January.
+var DefaultCalendar = Calendar{
+	WeekStart: time.Monday,
+	YearStart: time.January,
+}
+
+// MaxPeriodN is the largest multiple of a period unit.
+const MaxPeriodN = 1000000
+
+// Period is a positive multiple, up to MaxPeriodN, of a time unit.
+type Period struct {
+	N    int
+	Unit string
+	Calendar
+}
+
+// ParsePeriod parses a period string in the form <multiple><unit>.
+func ParsePeriod(period string) (Period, *errors.ErrResp) {
+	matches := periodRegexp.FindStringSubmatch(period)
+	if matches == nil {
+		return Period{}, errors.GetError(errors.UnsupportedPeriod)
+	}
+
+	n, err := strconv.Atoi(matches[1])
+	if err != nil || n <= 0 || n > MaxPeriodN {
+		return Period{}, errors.GetError(errors.UnsupportedPeriod)
+	}
+
+	return Period{
+		N:        n,
+		Unit:     matches[2],
+		Calendar: DefaultCalendar,
+	}, nil
+}
+
+// ParseWeekday parses an english weekday name, either in full or abbreviated, e.g. "sun" or "Sunday".
+func ParseWeekday(weekday string) (time.Weekday, *errors.ErrResp) {
+	weekday = strings.ToLower(weekday)
+	if len(weekday) >= 3 {
+		for day := time.Sunday; day <= time.Saturday; day++ {
+			name := strings.ToLower(day.String())
+			if strings.HasPrefix(name, weekday) {
+				return day, nil
+			}
+		}
+	}
+
+	return 0, errors.GetError(errors.UnsupportedWeekStart)
+}
+
+// ParseMonth parses a month number from 1 to 12, optionally zero padded, e.g. "4" or "04".
+func ParseMonth(month string) (time.Month, *errors.ErrResp) {
+	m, err := strconv.Atoi(month)
+	if err != nil || m < int(time.January) || m > int(time.December) {
+		return 0, errors.GetError(errors.UnsupportedYearStart)
+	}
+
+	return time.Month(m), nil
+}
@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"plist/errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// relativeRegexp matches relative time expressions such as now-7d, today or startofmonth-1mo.
+var relativeRegexp = regexp.MustCompile(`^(now|today|yesterday|tomorrow|startof(?:day|week|month|quarter|year))((?:[+-][0-9]+(?:m|h|d|w|mo|q|y))*)$`)
+
+// offsetRegexp matches a single offset of a relative time expression.
+var offsetRegexp = regexp.MustCompile(`([+-])([0-9]+)(mo|m|h|d|w|q|y)`)
+
+// ParseRelativeTime evaluates a relative time expression on the wall clock of the given location.
+//
+// The expression starts with now, today, yesterday, tomorrow or startofday, startofweek,
+// startofmonth, startofquarter, startofyear, followed by any number of offsets in period
+// units, e.g. now-7d, now+1mo or startofmonth-1mo. Week and year starts follow the calendar.
+// Offsets go up to MaxPeriodN units, like periods, and the result must fall within the years 1
+// to 9999 of absolute times.
+func ParseRelativeTime(expr string, now time.Time, loc *time.Location, calendar Calendar) (time.Time, *errors.ErrResp) {
+	matches := relativeRegexp.FindStringSubmatch(strings.ToLower(expr))
+	if matches == nil {
+		return time.Time{}, errors.GetError(errors.TimeParsingError)
+	}
+
+	if matches[1] == "now" && matches[2] == "" {
+		return now, nil
+	}
+
+	wallClock := WallClock(now.In(loc))
+	switch matches[1] {
+	case "today", "startofday":
+		wallClock = NormalizeTime(wallClock)
+	case "yesterday":
+		wallClock = NormalizeTime(wallClock).AddDate(0, 0, -1)
+	case "tomorrow":
+		wallClock = NormalizeTime(wallClock).AddDate(0, 0, 1)
+	case "startofweek":
+		wallClock, _ = truncate(wallClock, Period{N: 1, Unit: Week, Calendar: calendar})
+	case "startofmonth":
+		wallClock, _ = truncate(wallClock, Period{N: 1, Unit: Month, Calendar: calendar})
+	case "startofquarter":
+		wallClock, _ = truncate(wallClock, Period{N: 1, Unit: Quarter, Calendar: calendar})
+	case "startofyear":
+		wallClock, _ = truncate(wallClock, Period{N: 1, Unit: Year, Calendar: calendar})
+	}
+
+	for _, offset := range offsetRegexp.FindAllStringSubmatch(matches[2], -1) {
+		n, err := strconv.Atoi(offset[2])
+		if err != nil || n > MaxPeriodN {
+			return time.Time{}, errors.GetError(errors.TimeParsingError)
+		}
+		if offset[1] == "-" {
+			n = -n
+		}
+		if errResp := AddPeriod(&wallClock, Period{N: n, Unit: offset[3], Calendar: calendar}); errResp != nil {
+			return time.Time{}, errResp
+		}
+	}
+
+	if wallClock.Year() < 1 || wallClock.Year() > 9999 {
+		return time.Time{}, errors.GetError(errors.TimeParsingError)
+	}
+	return Resolve(wallClock, loc, DSTShiftForward)[0], nil
+}
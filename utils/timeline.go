@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"sort"
+	"time"
+)
+
+// Timeline resolves wall-clock readings, in increasing order, to instants in increasing order.
+// Around daylight saving overlaps the instants of consecutive readings interleave, e.g. with the
+// both policy 03:00 and 03:15 each occur once before and once after a fall back, so instants are
+// held back until no later reading can precede them.
+type Timeline struct {
+	loc     *time.Location
+	policy  DSTPolicy
+	pending []time.Time
+}
+
+// NewTimeline returns an empty timeline of the given location and DST policy.
+func NewTimeline(loc *time.Location, policy DSTPolicy) *Timeline {
+	return &Timeline{
+		loc:    loc,
+		policy: policy,
+	}
+}
+
+// Add resolves a wall-clock reading, not before the previous one, and calls fn in order with the
+// UTC instants that no reading from this one on can precede.
+func (tl *Timeline) Add(wallClock time.Time, fn func(time.Time)) {
+	// Offsets a day before and after enclose any single transition.
+	_, offsetBefore := wallClock.Add(-24 * time.Hour).In(tl.loc).Zone()
+	_, offsetAfter := wallClock.Add(24 * time.Hour).In(tl.loc).Zone()
+	offset := offsetBefore
+	if offsetAfter > offset {
+		offset = offsetAfter
+	}
+	tl.flush(wallClock.Add(-time.Duration(offset)*time.Second), fn)
+
+	for _, timeObj := range Resolve(wallClock, tl.loc, tl.policy) {
+		timeObj = timeObj.UTC()
+		i := sort.Search(len(tl.pending), func(i int) bool {
+			return tl.pending[i].After(timeObj)
+		})
+		tl.pending = append(tl.pending, time.Time{})
+		copy(tl.pending[i+1:], tl.pending[i:])
+		tl.pending[i] = timeObj
+	}
+}
+
+// Flush calls fn in order with all held back instants.
+func (tl *Timeline) Flush(fn func(time.Time)) {
+	for len(tl.pending) > 0 {
+		timeObj := tl.pending[0]
+		tl.pending = tl.pending[1:]
+		fn(timeObj)
+	}
+}
+
+// flush calls fn in order with the held back instants before the bound.
+func (tl *Timeline) flush(bound time.Time, fn func(time.Time)) {
+	for len(tl.pending) > 0 && tl.pending[0].Before(bound) {
+		timeObj := tl.pending[0]
+		tl.pending = tl.pending[1:]
+		fn(timeObj)
+	}
+}
+
+// Holds reports whether the timeline holds back an instant not after the given one.
+func (tl *Timeline) Holds(timeObj time.Time) bool {
+	return len(tl.pending) > 0 && !tl.pending[0].After(timeObj)
+}
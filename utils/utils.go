@@ -1,95 +1,144 @@
 package utils
 
 import (
-	"log"
 	"plist/errors"
-	"reflect"
 	"time"
 )
 
-// Round time objects to closest time objects based on the requirements.
-func Round(timeObj1, timeObj2 *time.Time, period string) *errors.ErrResp {
-	if timeObj1.Minute() > 0 {
-		*timeObj1 = time.Date(timeObj1.Year(), timeObj1.Month(), timeObj1.Day(), timeObj1.Add(time.Hour).Hour(), 0, 0, 0, timeObj1.Location())
+// Round time objects to the period boundaries that enclose them. Both time objects are
+// local wall-clock times (see WallClock): timeObj1 moves forward to the first boundary at or
+// after it and timeObj2 moves back to the last boundary at or before it.
+//
+// Multiples are aligned to a stable anchor, so 15m always falls on the quarter hours, 2h on
+// even hours, 3d on every third day since 1970-01-01, 1w on the first day of the week,
+// 2mo on Jan/Mar/May/..., 1q on Jan/Apr/Jul/Oct and 5y on years divisible by five.
+// Month, quarter and year periods are counted from the first month of the year, so with
+// a year starting in April 1q falls on Apr/Jul/Oct/Jan and 1y on April 1st.
+func Round(timeObj1, timeObj2 *time.Time, period Period) *errors.ErrResp {
+	start, ok := truncate(*timeObj1, period)
+	if !ok {
+		return errors.GetError(errors.TimeRoundingError)
 	}
-
-	switch period {
-	case "1h":
-		if timeObj2.Minute() > 0 {
-			*timeObj2 = time.Date(timeObj2.Year(), timeObj2.Month(), timeObj2.Day(), timeObj2.Hour(), 0, 0, 0, timeObj2.Location())
-		}
-		return nil
-	case "1d":
-		if timeObj2.Minute() > 0 {
-			*timeObj2 = time.Date(timeObj2.Year(), timeObj2.Month(), timeObj2.Day()-1, timeObj1.Hour(), 0, 0, 0, timeObj2.Location())
-		}
-		return nil
-	case "1mo":
-		// Last day of the month trick :)
-		*timeObj1 = time.Date(timeObj1.Year(), timeObj1.Month()+1, 0, timeObj1.Hour(), 0, 0, 0, timeObj1.Location())
-
-		if timeObj2.Day() > 0 {
-			*timeObj2 = time.Date(timeObj2.Year(), timeObj2.Month(), 0, timeObj1.Hour(), 0, 0, 0, timeObj2.Location())
+	if start.Before(*timeObj1) {
+		if errResp := AddPeriod(&start, period); errResp != nil {
+			return errResp
 		}
+	}
 
-		return nil
-	case "1y":
-		// Last month and day of the month trick :)
-		*timeObj1 = time.Date(timeObj1.Year()+1, 1, 0, timeObj1.Hour(), 0, 0, 0, timeObj1.Location())
-
-		if timeObj2.Month() > 0 {
-			*timeObj2 = time.Date(timeObj2.Year(), 1, 0, timeObj1.Hour(), 0, 0, 0, timeObj2.Location())
-		}
-		return nil
+	end, ok := truncate(*timeObj2, period)
+	if !ok {
+		return errors.GetError(errors.TimeRoundingError)
 	}
 
-	return errors.GetError(errors.TimeRoundingError)
+	*timeObj1 = start
+	*timeObj2 = end
+	return nil
 }
 
 // Add periods of time to time object until that reaches the end of given time.
-func AddPeriod(timeObj *time.Time, period string) *errors.ErrResp {
-	switch period {
-	case "1h":
-		*timeObj = timeObj.Add(time.Hour)
+func AddPeriod(timeObj *time.Time, period Period) *errors.ErrResp {
+	switch period.Unit {
+	case Minute:
+		*timeObj = addClock(*timeObj, 0, period.N)
+		return nil
+	case Hour:
+		*timeObj = addClock(*timeObj, period.N, 0)
 		return nil
-	case "1d":
-		*timeObj = timeObj.AddDate(0, 0, 1)
+	case Day:
+		*timeObj = timeObj.AddDate(0, 0, period.N)
 		return nil
-	case "1mo":
-		*timeObj = time.Date(timeObj.Year(), timeObj.Month()+2, 0, timeObj.Hour(), 0, 0, 0, timeObj.Location())
+	case Week:
+		*timeObj = timeObj.AddDate(0, 0, 7*period.N)
 		return nil
-	case "1y":
-		*timeObj = time.Date(timeObj.Year()+1, 1, 0, timeObj.Hour(), 0, 0, 0, timeObj.Location())
-		*timeObj = timeObj.AddDate(1, 0, 0)
+	case Month:
+		*timeObj = timeObj.AddDate(0, period.N, 0)
+		return nil
+	case Quarter:
+		*timeObj = timeObj.AddDate(0, 3*period.N, 0)
+		return nil
+	case Year:
+		*timeObj = timeObj.AddDate(period.N, 0, 0)
 		return nil
 	}
 
 	return errors.GetError(errors.AddingPeriodError)
 }
 
-func NormalizeTime(timeObjLocal, timeObjUTC time.Time) (time.Time, time.Time) {
-	if timeObjLocal.Format("20060102") > timeObjUTC.Format("20060102") {
-		timeObjLocal = time.Date(timeObjLocal.Year(), timeObjLocal.Month(), timeObjLocal.Day(), 0, 0, 0, 0, timeObjLocal.Location())
-		timeObjUTC = timeObjLocal.UTC()
-	} else if timeObjLocal.Format("20060102") < timeObjUTC.Format("20060102") {
-		timeObjLocal = time.Date(timeObjLocal.Year(), timeObjLocal.Month(), timeObjLocal.Day(), 24, 0, 0, 0, timeObjLocal.Location())
-		timeObjUTC = timeObjLocal.UTC()
+// NormalizeTime moves a wall-clock time back to the local midnight of its day.
+func NormalizeTime(timeObj time.Time) time.Time {
+	return time.Date(timeObj.Year(), timeObj.Month(), timeObj.Day(), 0, 0, 0, 0, timeObj.Location())
+}
+
+// WallClock returns the local wall-clock reading of a time object as a UTC time object, so that
+// period arithmetic is not affected by daylight saving transitions.
+func WallClock(timeObj time.Time) time.Time {
+	return time.Date(timeObj.Year(), timeObj.Month(), timeObj.Day(), timeObj.Hour(), timeObj.Minute(), timeObj.Second(), timeObj.Nanosecond(), time.UTC)
+}
+
+// addClock adds hours and minutes to a wall-clock time. Unlike time.Duration arithmetic it does
+// not overflow on periods longer than 292 years.
+func addClock(timeObj time.Time, hours, minutes int) time.Time {
+	return time.Date(timeObj.Year(), timeObj.Month(), timeObj.Day(), timeObj.Hour()+hours, timeObj.Minute()+minutes, timeObj.Second(), timeObj.Nanosecond(), timeObj.Location())
+}
+
+// truncate moves a wall-clock time back to the closest aligned period boundary.
+func truncate(timeObj time.Time, period Period) (time.Time, bool) {
+	switch period.Unit {
+	case Minute:
+		return truncateSeconds(timeObj, int64(period.N)*60), true
+	case Hour:
+		return truncateSeconds(timeObj, int64(period.N)*60*60), true
+	case Day:
+		days := FloorDiv(NormalizeTime(timeObj).Unix(), 24*60*60)
+		days -= FloorMod(days, int64(period.N))
+		return time.Unix(days*24*60*60, 0).UTC(), true
+	case Week:
+		// 1970-01-01 was a Thursday.
+		days := FloorDiv(NormalizeTime(timeObj).Unix(), 24*60*60)
+		firstWeekStart := FloorMod(int64(period.WeekStart-time.Thursday), 7)
+		weeks := FloorDiv(days-firstWeekStart, 7)
+		weeks -= FloorMod(weeks, int64(period.N))
+		return time.Unix((firstWeekStart+weeks*7)*24*60*60, 0).UTC(), true
+	case Month:
+		return truncateMonths(timeObj, period.N, period.YearStart), true
+	case Quarter:
+		return truncateMonths(timeObj, 3*period.N, period.YearStart), true
+	case Year:
+		return truncateMonths(timeObj, 12*period.N, period.YearStart), true
 	}
-	return timeObjLocal, timeObjUTC
+
+	return time.Time{}, false
 }
 
-// Checks for errors.
-func CheckErr(err interface{}) bool {
-	if err != nil && !reflect.ValueOf(err).IsNil() {
-		errorType := reflect.TypeOf(err).Elem().String()
-		switch errorType {
-		case "errors.errorString":
-			log.Println(err)
-			return true
-		case "errors.ErrResp":
-			log.Println(err)
-			return true
-		}
+// truncateMonths moves a wall-clock time back to the first day of a multiple of n months
+// since the first month of the year.
+func truncateMonths(timeObj time.Time, n int, yearStart time.Month) time.Time {
+	months := int64(timeObj.Year())*12 + int64(timeObj.Month()-yearStart)
+	months -= FloorMod(months, int64(n))
+	return time.Date(int(FloorDiv(months, 12)), time.Month(FloorMod(months, 12))+yearStart, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// truncateSeconds moves a wall-clock time back to a multiple of step seconds since 1970-01-01.
+func truncateSeconds(timeObj time.Time, step int64) time.Time {
+	seconds := timeObj.Unix()
+	return time.Unix(seconds-FloorMod(seconds, step), 0).UTC()
+}
+
+// FloorDiv divides a by b > 0, rounding down.
+func FloorDiv(a, b int64) int64 {
+	return (a - FloorMod(a, b)) / b
+}
+
+// CeilDiv divides a by b > 0, rounding up.
+func CeilDiv(a, b int64) int64 {
+	return -FloorDiv(-a, b)
+}
+
+// FloorMod returns the remainder of a divided by b > 0, between 0 and b.
+func FloorMod(a, b int64) int64 {
+	m := a % b
+	if m < 0 {
+		m += b
 	}
-	return false
+	return m
 }